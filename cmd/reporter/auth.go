@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+	"github.com/Hikitak/figma-comment-reporter/pkg/oauth"
+)
+
+// runAuth проводит однократную авторизацию OAuth-приложения и сохраняет
+// токены в token_file. Код можно передать флагом -code, иначе он
+// принимается локальным сервером на redirect_url.
+func runAuth(args []string) {
+	fs := flag.NewFlagSet("auth", flag.ExitOnError)
+	code := fs.String("code", "", "authorization code copied from the redirect URL")
+	fs.Parse(args)
+
+	cfg, err := config.Load(configPath(fs.Args()))
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Figma.OAuth.ClientID == "" {
+		log.Fatal("figma.oauth.client_id is not configured")
+	}
+	oauthCfg := newOAuthConfig(cfg.Figma.OAuth)
+
+	if *code == "" {
+		*code, err = waitForCode(oauthCfg)
+		if err != nil {
+			log.Fatalf("Authorization failed: %v", err)
+		}
+	}
+
+	token, err := oauthCfg.Exchange(*code)
+	if err != nil {
+		log.Fatalf("Failed to exchange authorization code: %v", err)
+	}
+	if err := oauth.SaveToken(oauthCfg.TokenFile, token); err != nil {
+		log.Fatalf("Failed to save token: %v", err)
+	}
+	log.Printf("Token saved to %s", oauthCfg.TokenFile)
+}
+
+func waitForCode(cfg oauth.Config) (string, error) {
+	redirect, err := url.Parse(cfg.RedirectURL)
+	if err != nil || redirect.Host == "" {
+		return "", fmt.Errorf("invalid redirect_url %q, pass the code with -code instead", cfg.RedirectURL)
+	}
+
+	stateBytes := make([]byte, 16)
+	if _, err := rand.Read(stateBytes); err != nil {
+		return "", err
+	}
+	state := hex.EncodeToString(stateBytes)
+
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return "", fmt.Errorf("listening on %s: %w", redirect.Host, err)
+	}
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+
+	mux := http.NewServeMux()
+	path := redirect.Path
+	if path == "" {
+		path = "/"
+	}
+	// Браузер может открыть redirect повторно; принимается только первый
+	// ответ, остальные не должны блокировать обработчик
+	deliver := func(res result) {
+		select {
+		case results <- res:
+		default:
+		}
+	}
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case query.Get("state") != state:
+			http.Error(w, "state mismatch", http.StatusBadRequest)
+			return
+		case query.Get("error") != "":
+			deliver(result{err: fmt.Errorf("authorization denied: %s", query.Get("error"))})
+		case query.Get("code") == "":
+			http.Error(w, "missing code", http.StatusBadRequest)
+			return
+		default:
+			deliver(result{code: query.Get("code")})
+		}
+		fmt.Fprintln(w, "Authorization complete, you can close this window.")
+	})
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}()
+
+	fmt.Printf("Open this URL in a browser to authorize the reporter:\n\n%s\n\n", cfg.AuthCodeURL(state))
+
+	res := <-results
+	return res.code, res.err
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/cache"
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+	"github.com/Hikitak/figma-comment-reporter/pkg/email"
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+	"github.com/Hikitak/figma-comment-reporter/pkg/oauth"
+	"github.com/Hikitak/figma-comment-reporter/pkg/reporter"
+)
+
+func newFigmaClient(cfg *config.Config) *figma.Client {
+	figmaClient := figma.NewClient(cfg.Figma.Token)
+	if cfg.Figma.BaseURL != "" {
+		figmaClient.BaseURL = cfg.Figma.BaseURL
+	}
+	figmaClient.UserAgent = cfg.Figma.UserAgent
+	if cfg.Figma.MaxRetries != nil {
+		figmaClient.Retry.MaxRetries = *cfg.Figma.MaxRetries
+	}
+	if cfg.Figma.RetryBudget != nil {
+		figmaClient.Retry.Budget = *cfg.Figma.RetryBudget
+	}
+	if cfg.Figma.MaxURLLength > 0 {
+		figmaClient.MaxURLLength = cfg.Figma.MaxURLLength
+	}
+	if cfg.Figma.NodeConcurrency > 0 {
+		figmaClient.NodeConcurrency = cfg.Figma.NodeConcurrency
+	}
+	if cfg.Figma.OAuth.ClientID != "" {
+		figmaClient.TokenSource = oauth.NewTokenSource(newOAuthConfig(cfg.Figma.OAuth))
+	}
+	return figmaClient
+}
+
+func newOAuthConfig(cfg config.OAuthConfig) oauth.Config {
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"files:read"}
+	}
+	tokenFile := cfg.TokenFile
+	if tokenFile == "" {
+		tokenFile = "figma_token.json"
+	}
+
+	return oauth.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       scopes,
+		TokenFile:    tokenFile,
+		AuthURL:      cfg.AuthURL,
+		TokenURL:     cfg.TokenURL,
+		RefreshURL:   cfg.RefreshURL,
+	}
+}
+
+func newReporter(cfg *config.Config, figmaClient *figma.Client) *reporter.Reporter {
+	figmaReporter := reporter.New(
+		figmaClient,
+		cfg.Figma.FileKeys,
+		cfg.Report.Fields,
+	)
+	figmaReporter.Files = cfg.Figma.Files
+	figmaReporter.IncludeUnanchored = cfg.Report.IncludeUnanchored
+	figmaReporter.TeamIDs = cfg.Figma.TeamIDs
+	figmaReporter.ProjectIDs = cfg.Figma.ProjectIDs
+	figmaReporter.Discovery = cfg.Figma.Discovery
+	figmaReporter.Sort = cfg.Report.Sort
+	figmaReporter.Filters = cfg.Report.Filters
+	figmaReporter.Thumbnails = cfg.Report.Thumbnails
+	figmaReporter.Markdown = cfg.Report.Markdown
+	figmaReporter.SinceVersion = cfg.Report.SinceVersion
+	figmaReporter.Branches = cfg.Figma.Branches
+	if cfg.Figma.Cache.Dir != "" {
+		figmaReporter.Cache = cache.New(cfg.Figma.Cache.Dir, cfg.Figma.Cache.TTL)
+	}
+	return figmaReporter
+}
+
+// delivery — получатели одного формата отчёта.
+type delivery struct {
+	Renderer reporter.Renderer
+	To       []string
+}
+
+// newDeliveries группирует email.to и email.recipients по формату
+// вложения; email.to получает report.format.
+func newDeliveries(cfg *config.Config) ([]delivery, error) {
+	var deliveries []delivery
+	index := make(map[string]int)
+
+	add := func(format, address string) error {
+		if format == "" {
+			format = cfg.Report.Format
+		}
+		renderer, err := reporter.NewRenderer(format)
+		if err != nil {
+			return err
+		}
+		key := renderer.Extension()
+		i, ok := index[key]
+		if !ok {
+			i = len(deliveries)
+			index[key] = i
+			deliveries = append(deliveries, delivery{Renderer: renderer})
+		}
+		deliveries[i].To = append(deliveries[i].To, address)
+		return nil
+	}
+
+	// Каждый адрес получает одно письмо; запись в email.recipients
+	// важнее email.to, адреса сравниваются без учёта регистра
+	type recipient struct {
+		address, format string
+		explicit        bool
+	}
+	var recipients []recipient
+	seen := make(map[string]int)
+	set := func(r recipient) {
+		key := strings.ToLower(strings.TrimSpace(r.address))
+		if i, ok := seen[key]; ok {
+			recipients[i].format, recipients[i].explicit = r.format, r.explicit
+			return
+		}
+		seen[key] = len(recipients)
+		recipients = append(recipients, r)
+	}
+	for _, address := range cfg.Email.To {
+		set(recipient{address: address})
+	}
+	for _, r := range cfg.Email.Recipients {
+		set(recipient{address: r.Address, format: r.Format, explicit: true})
+	}
+
+	for _, r := range recipients {
+		if err := add(r.format, r.address); err != nil {
+			if r.explicit {
+				return nil, fmt.Errorf("recipient %s: %w", r.address, err)
+			}
+			return nil, err
+		}
+	}
+	return deliveries, nil
+}
+
+func newEmailSender(cfg *config.Config) *email.Sender {
+	return email.NewSender(email.Config{
+		SMTPHost:     cfg.Email.SMTPHost,
+		SMTPPort:     cfg.Email.SMTPPort,
+		SMTPUsername: cfg.Email.SMTPUsername,
+		SMTPPassword: cfg.Email.SMTPPassword,
+		From:         cfg.Email.From,
+		To:           cfg.Email.To,
+		Subject:      cfg.Email.Subject,
+		Body:         cfg.Email.Body,
+	})
+}
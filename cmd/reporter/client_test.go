@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+)
+
+func TestNewDeliveries(t *testing.T) {
+	tests := []struct {
+		name       string
+		format     string
+		to         []string
+		recipients []config.EmailRecipient
+		// Формат и адреса каждого письма: "csv: a, b"
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "default format",
+			to:     []string{"a@x.io", "b@x.io"},
+			want:   []string{"xlsx: a@x.io, b@x.io"},
+			format: "",
+		},
+		{
+			name:       "grouped by format",
+			format:     "csv",
+			to:         []string{"a@x.io"},
+			recipients: []config.EmailRecipient{{Address: "b@x.io", Format: "pdf"}, {Address: "c@x.io", Format: "CSV"}},
+			want:       []string{"csv: a@x.io, c@x.io", "pdf: b@x.io"},
+		},
+		{
+			name:       "recipient overrides to",
+			format:     "xlsx",
+			to:         []string{"a@x.io", "Lead@X.io"},
+			recipients: []config.EmailRecipient{{Address: "lead@x.io", Format: "pdf"}},
+			want:       []string{"xlsx: a@x.io", "pdf: Lead@X.io"},
+		},
+		{
+			name:   "duplicate in to",
+			format: "html",
+			to:     []string{"a@x.io", "A@x.io "},
+			want:   []string{"html: a@x.io"},
+		},
+		{
+			name:       "unknown format",
+			recipients: []config.EmailRecipient{{Address: "a@x.io", Format: "docx"}},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Report.Format = tt.format
+			cfg.Email.To = tt.to
+			cfg.Email.Recipients = tt.recipients
+
+			deliveries, err := newDeliveries(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, d := range deliveries {
+				got = append(got, d.Renderer.Extension()+": "+strings.Join(d.To, ", "))
+			}
+			if strings.Join(got, "; ") != strings.Join(tt.want, "; ") {
+				t.Errorf("deliveries = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
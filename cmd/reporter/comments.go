@@ -0,0 +1,172 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+	"github.com/Hikitak/figma-comment-reporter/pkg/reporter"
+)
+
+type whereFlags []config.FilterRule
+
+func (w *whereFlags) String() string {
+	return fmt.Sprint(*w)
+}
+
+// Set разбирает условие "field op value": "status = open", "status=open"
+// или "message contains two  words". Значение — весь остаток строки
+// после оператора.
+func (w *whereFlags) Set(value string) error {
+	rule, ok := parseWhere(value)
+	if !ok {
+		return fmt.Errorf("expected \"field op value\", got %q", value)
+	}
+	*w = append(*w, rule)
+	return nil
+}
+
+var whereOps = []string{"=", "!=", ">", ">=", "<", "<=", "contains", "glob", "has"}
+
+func parseWhere(s string) (config.FilterRule, bool) {
+	s = strings.TrimSpace(s)
+
+	// Оператор отделён пробелами
+	if fields := strings.Fields(s); len(fields) >= 3 && slices.Contains(whereOps, fields[1]) {
+		rest := strings.TrimSpace(s[len(fields[0]):])
+		return config.FilterRule{
+			Field: fields[0],
+			Op:    fields[1],
+			Value: strings.TrimSpace(rest[len(fields[1]):]),
+		}, true
+	}
+
+	// Слитная запись вроде "status=open" или "reaction_count>=2"
+	i := strings.IndexAny(s, "=!<>")
+	if i <= 0 {
+		return config.FilterRule{}, false
+	}
+	op := s[i : i+1]
+	if i+1 < len(s) && s[i+1] == '=' {
+		op = s[i : i+2]
+	}
+	field := strings.TrimSpace(s[:i])
+	if !slices.Contains(whereOps, op) || strings.ContainsAny(field, " \t") {
+		return config.FilterRule{}, false
+	}
+	return config.FilterRule{Field: field, Op: op, Value: strings.TrimSpace(s[i+len(op):])}, true
+}
+
+// runComments изменяет комментарии в Figma: reply или delete.
+// Сначала всегда печатается список изменений; записываются они только
+// с флагом -apply.
+func runComments(args []string) {
+	if len(args) == 0 {
+		log.Fatal("usage: reporter comments reply|delete [flags] [config.yaml]")
+	}
+	action := args[0]
+
+	fs := flag.NewFlagSet("comments "+action, flag.ExitOnError)
+	fileKey := fs.String("file", "", "file key of a single comment (with -id)")
+	commentID := fs.String("id", "", "ID of a single comment to act on")
+	message := fs.String("message", "", "reply text")
+	apply := fs.Bool("apply", false, "write the changes; without it only the dry-run preview is printed")
+	var where whereFlags
+	fs.Var(&where, "where", "select comments by a report field, e.g. \"status = open\" (repeatable)")
+	fs.Parse(args[1:])
+
+	switch action {
+	case "reply":
+		if *message == "" {
+			log.Fatal("reply needs -message")
+		}
+	case "delete":
+	default:
+		log.Fatalf("unknown comments action %q, expected reply or delete", action)
+	}
+
+	cfg, err := config.Load(configPath(fs.Args()))
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	figmaClient := newFigmaClient(cfg)
+
+	var selections []reporter.Selection
+	switch {
+	case *commentID != "":
+		if *fileKey == "" {
+			log.Fatal("-id needs -file")
+		}
+		selection, err := newReporter(cfg, figmaClient).SelectComment(*fileKey, *commentID)
+		if err != nil {
+			log.Fatal(err)
+		}
+		selections = append(selections, selection)
+	case len(where) > 0:
+		figmaReporter := newReporter(cfg, figmaClient)
+		figmaReporter.Filters = where
+		selections, err = figmaReporter.Select()
+		if err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	default:
+		log.Fatal("pass -id with -file for a single comment or at least one -where for a selection")
+	}
+
+	mode := "dry-run"
+	if *apply {
+		mode = "apply"
+	}
+	fmt.Printf("[%s] %d comment(s) selected for %s\n", mode, len(selections), action)
+	for _, s := range selections {
+		root := s.Thread.Root
+		fmt.Printf("  %s %s/%s by %s on %q: %q", action, s.FileKey, root.ID, root.User.Handle, s.NodeName, preview(root.Message))
+		switch action {
+		case "reply":
+			fmt.Printf(" <- %q", preview(*message))
+		case "delete":
+			if n := s.Thread.ReplyCount(); n > 0 {
+				fmt.Printf(" (with %d replies)", n)
+			}
+		}
+		fmt.Println()
+	}
+
+	if !*apply {
+		fmt.Println("Nothing was changed. Re-run with -apply to write these changes.")
+		return
+	}
+
+	failed := 0
+	for _, s := range selections {
+		root := s.Thread.Root
+		var err error
+		switch action {
+		case "reply":
+			// Ответить можно только в тред, то есть корневому комментарию
+			threadID := root.ID
+			if root.ParentID != "" {
+				threadID = root.ParentID
+			}
+			_, err = s.Client.PostReply(s.FileKey, threadID, *message)
+		case "delete":
+			err = s.Client.DeleteComment(s.FileKey, root.ID)
+		}
+		if err != nil {
+			failed++
+			log.Printf("Error on comment %s/%s: %v", s.FileKey, root.ID, err)
+		}
+	}
+	fmt.Printf("Done: %d changed, %d failed\n", len(selections)-failed, failed)
+}
+
+func preview(message string) string {
+	message = strings.Join(strings.Fields(message), " ")
+	if runes := []rune(message); len(runes) > 60 {
+		return string(runes[:57]) + "..."
+	}
+	return message
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+)
+
+func TestParseWhere(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   config.FilterRule
+		wantOK bool
+	}{
+		{"status = open", config.FilterRule{Field: "status", Op: "=", Value: "open"}, true},
+		{"status=open", config.FilterRule{Field: "status", Op: "=", Value: "open"}, true},
+		{"  status   =   open ", config.FilterRule{Field: "status", Op: "=", Value: "open"}, true},
+		{"status =open", config.FilterRule{Field: "status", Op: "=", Value: "open"}, true},
+		{"reaction_count>=2", config.FilterRule{Field: "reaction_count", Op: ">=", Value: "2"}, true},
+		{"status!=resolved", config.FilterRule{Field: "status", Op: "!=", Value: "resolved"}, true},
+		{"message contains two  words", config.FilterRule{Field: "message", Op: "contains", Value: "two  words"}, true},
+		{"message contains a=b", config.FilterRule{Field: "message", Op: "contains", Value: "a=b"}, true},
+		{"created_at < 2024-01-01T00:00:00Z", config.FilterRule{Field: "created_at", Op: "<", Value: "2024-01-01T00:00:00Z"}, true},
+		{"branch_name =", config.FilterRule{Field: "branch_name", Op: "=", Value: ""}, true},
+		{"status", config.FilterRule{}, false},
+		{"=open", config.FilterRule{}, false},
+		{"status ! open", config.FilterRule{}, false},
+		{"node name = Card", config.FilterRule{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseWhere(tt.in)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("parseWhere(%q) = %+v, %v; want %+v, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestPreview(t *testing.T) {
+	long := "word word word word word word word word word word word word word"
+	tests := map[string]string{
+		"Fix\n the   button": "Fix the button",
+		long:                 "word word word word word word word word word word word wo...",
+	}
+	for in, want := range tests {
+		if got := preview(in); got != want {
+			t.Errorf("preview(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -1,58 +1,107 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"os"
+	"strings"
 
-	"github.com/robfig/cron/v3"
 	"github.com/Hikitak/figma-comment-reporter/pkg/config"
-	"github.com/Hikitak/figma-comment-reporter/pkg/email"
 	"github.com/Hikitak/figma-comment-reporter/pkg/reporter"
+	"github.com/robfig/cron/v3"
 )
 
 func main() {
-	cfgPath := "config.yaml"
-	if len(os.Args) > 1 {
-		cfgPath = os.Args[1]
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case "auth":
+			runAuth(args[1:])
+			return
+		case "comments":
+			runComments(args[1:])
+			return
+		case "serve":
+			runServe(args[1:])
+			return
+		case "webhooks":
+			runWebhooks(args[1:])
+			return
+		}
 	}
 
-	cfg, err := config.Load(cfgPath)
+	fs := flag.NewFlagSet("reporter", flag.ExitOnError)
+	noCache := fs.Bool("no-cache", false, "ignore figma.cache and cached thumbnails and fetch everything from Figma")
+	fs.Parse(args)
+
+	cfg, err := config.Load(configPath(fs.Args()))
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	figmaReporter := reporter.New(
-		cfg.Figma.Token,
-		cfg.Figma.FileKeys,
-		cfg.Report.Fields,
-	)
-
-	emailSender := email.NewSender(email.Config{
-		SMTPHost:     cfg.Email.SMTPHost,
-		SMTPPort:     cfg.Email.SMTPPort,
-		SMTPUsername: cfg.Email.SMTPUsername,
-		SMTPPassword: cfg.Email.SMTPPassword,
-		From:         cfg.Email.From,
-		To:           cfg.Email.To,
-		Subject:      cfg.Email.Subject,
-		Body:         cfg.Email.Body,
-	})
+	figmaReporter := newReporter(cfg, newFigmaClient(cfg))
+	if *noCache {
+		figmaReporter.Cache = nil
+		figmaReporter.RefreshThumbnails = true
+	}
+
+	deliveries, err := newDeliveries(cfg)
+	if err != nil {
+		log.Fatalf("Invalid report format: %v", err)
+	}
+
+	// Markdown-версия отчёта в тексте письма
+	var markdown reporter.Renderer
+	if cfg.Email.MarkdownBody {
+		if markdown, err = reporter.NewRenderer("markdown"); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	emailSender := newEmailSender(cfg)
 
 	// Запуск по расписанию
 	c := cron.New()
 	c.AddFunc(cfg.Schedule, func() {
 		log.Println("Generating report...")
-		xlsxData, err := figmaReporter.Generate()
+		var renderers []reporter.Renderer
+		for _, d := range deliveries {
+			renderers = append(renderers, d.Renderer)
+		}
+		if markdown != nil {
+			renderers = append(renderers, markdown)
+		}
+		report, err := figmaReporter.Collect(renderers...)
 		if err != nil {
 			log.Printf("Error generating report: %v", err)
 			return
 		}
 
-		log.Println("Sending email...")
-		if err := emailSender.Send(xlsxData, "figma_comments.xlsx"); err != nil {
-			log.Printf("Error sending email: %v", err)
-		} else {
-			log.Println("Email sent successfully")
+		body := cfg.Email.Body
+		if markdown != nil {
+			text, err := markdown.Render(report)
+			if err != nil {
+				log.Printf("Error rendering Markdown body: %v", err)
+			} else {
+				body += "\n\n" + string(text)
+			}
+		}
+
+		// Отчёт собирается один раз и отправляется в формате каждого получателя
+		for _, d := range deliveries {
+			data, err := d.Renderer.Render(report)
+			if err != nil {
+				log.Printf("Error rendering %s report: %v", d.Renderer.Extension(), err)
+				continue
+			}
+
+			log.Printf("Sending %s report to %s...", d.Renderer.Extension(), strings.Join(d.To, ", "))
+			filename := "figma_comments." + d.Renderer.Extension()
+			if err := emailSender.SendTo(d.To, body, data, filename, d.Renderer.MIMEType()); err != nil {
+				log.Printf("Error sending email: %v", err)
+			} else {
+				log.Println("Email sent successfully")
+			}
 		}
 	})
 
@@ -61,4 +110,11 @@ func main() {
 
 	// Поддерживаем работу приложения
 	select {}
-}
\ No newline at end of file
+}
+
+func configPath(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return "config.yaml"
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+	"github.com/Hikitak/figma-comment-reporter/pkg/notify"
+	"github.com/Hikitak/figma-comment-reporter/pkg/webhook"
+)
+
+const defaultWebhookPath = "/figma/webhook"
+
+// placeholderPasscode — заглушка из старых примеров конфигурации, с ней
+// вебхуки принял бы любой, кто видел пример.
+const placeholderPasscode = "change_me"
+
+func checkPasscode(passcode string) error {
+	switch passcode {
+	case "":
+		return errors.New("webhook.passcode is not configured, generate one with `reporter webhooks create`")
+	case placeholderPasscode:
+		return fmt.Errorf("webhook.passcode is the example value %q, replace it with a random secret", placeholderPasscode)
+	}
+	return nil
+}
+
+// runServe запускает HTTP-сервер, принимающий вебхуки Figma.
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := fs.String("listen", "", "address to listen on, overrides webhook.listen")
+	fs.Parse(args)
+
+	cfg, err := config.Load(configPath(fs.Args()))
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if err := checkPasscode(cfg.Webhook.Passcode); err != nil {
+		log.Fatal(err)
+	}
+
+	addr := cfg.Webhook.Listen
+	if *listen != "" {
+		addr = *listen
+	}
+	if addr == "" {
+		addr = ":8080"
+	}
+	path := cfg.Webhook.Path
+	if path == "" {
+		path = defaultWebhookPath
+	}
+
+	var notifiers []notify.Notifier
+	if cfg.Webhook.Notify.Email {
+		notifiers = append(notifiers, notify.Email{Sender: newEmailSender(cfg)})
+	}
+	for _, url := range cfg.Webhook.Notify.URLs {
+		notifiers = append(notifiers, notify.HTTP{URL: url})
+	}
+
+	var store *webhook.Store
+	if cfg.Webhook.StateFile != "" {
+		store = webhook.NewStore(cfg.Webhook.StateFile)
+	}
+
+	handler := webhook.NewHandler(cfg.Webhook.Passcode, notifiers, store)
+	mux := http.NewServeMux()
+	mux.Handle(path, handler)
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+
+	// По сигналу сервер перестаёт принимать запросы и дожидается
+	// уведомлений по уже принятым событиям
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("Error shutting down webhook server: %v", err)
+		}
+	}()
+
+	log.Printf("Listening for Figma webhooks on %s%s", addr, path)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+	handler.Wait()
+	log.Print("Webhook server stopped")
+}
@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestCheckPasscode(t *testing.T) {
+	tests := []struct {
+		passcode string
+		wantErr  bool
+	}{
+		{"", true},
+		{"change_me", true},
+		{"4f9c2d0e7b1a", false},
+	}
+	for _, tt := range tests {
+		if err := checkPasscode(tt.passcode); (err != nil) != tt.wantErr {
+			t.Errorf("checkPasscode(%q) = %v, want error %v", tt.passcode, err, tt.wantErr)
+		}
+	}
+}
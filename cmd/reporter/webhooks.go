@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+	"github.com/Hikitak/figma-comment-reporter/pkg/webhook"
+)
+
+// runWebhooks управляет подписками на вебхуки Figma для команд
+// из figma.team_ids.
+func runWebhooks(args []string) {
+	if len(args) == 0 {
+		log.Fatal("usage: reporter webhooks list|create|delete|ping [flags] [config.yaml]")
+	}
+	action := args[0]
+
+	fs := flag.NewFlagSet("webhooks "+action, flag.ExitOnError)
+	webhookID := fs.String("id", "", "webhook ID to delete")
+	fs.Parse(args[1:])
+
+	cfg, err := config.Load(configPath(fs.Args()))
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	figmaClient := newFigmaClient(cfg)
+
+	switch action {
+	case "list":
+		listWebhooks(cfg, figmaClient)
+	case "create":
+		createWebhooks(cfg, figmaClient)
+	case "delete":
+		if *webhookID == "" {
+			log.Fatal("delete needs -id")
+		}
+		if err := figmaClient.DeleteWebhook(*webhookID); err != nil {
+			log.Fatalf("Failed to delete webhook %s: %v", *webhookID, err)
+		}
+		fmt.Printf("Deleted webhook %s\n", *webhookID)
+	case "ping":
+		pingWebhook(cfg)
+	default:
+		log.Fatalf("unknown webhooks action %q, expected list, create, delete or ping", action)
+	}
+}
+
+// Состояния подписки относительно конфига.
+const (
+	webhookOK = "ok"
+	// Наш endpoint, но Figma не отправляет события
+	webhookPaused = "paused"
+	// Наш endpoint с другим passcode: serve отклонит события с 403
+	webhookPasscode = "passcode"
+	// Наш endpoint, но другое событие или состояние
+	webhookUnexpected = "unexpected"
+	webhookForeign    = "foreign"
+)
+
+// webhookState сравнивает подписку с конфигом: ожидается событие
+// FILE_COMMENT на webhook.endpoint с webhook.passcode в активном состоянии.
+func webhookState(cfg *config.Config, w figma.Webhook) string {
+	switch {
+	case w.Endpoint != cfg.Webhook.Endpoint:
+		return webhookForeign
+	case w.EventType != webhook.EventFileComment:
+		return webhookUnexpected
+	case w.Passcode != cfg.Webhook.Passcode:
+		return webhookPasscode
+	case w.Status == "PAUSED":
+		return webhookPaused
+	case w.Status != "ACTIVE":
+		return webhookUnexpected
+	}
+	return webhookOK
+}
+
+// teamWebhook возвращает подписку команды на наш endpoint, ради которой
+// не нужно создавать новую: рабочую, приостановленную или с другим
+// passcode, в этом порядке. ok — false, если такой нет.
+func teamWebhook(cfg *config.Config, webhooks []figma.Webhook) (figma.Webhook, string, bool) {
+	for _, state := range []string{webhookOK, webhookPaused, webhookPasscode} {
+		for _, w := range webhooks {
+			if webhookState(cfg, w) == state {
+				return w, state, true
+			}
+		}
+	}
+	return figma.Webhook{}, "", false
+}
+
+func listWebhooks(cfg *config.Config, figmaClient *figma.Client) {
+	if len(cfg.Figma.TeamIDs) == 0 {
+		log.Fatal("figma.team_ids is not configured")
+	}
+
+	drift := 0
+	for _, teamID := range cfg.Figma.TeamIDs {
+		webhooks, err := figmaClient.ListTeamWebhooks(teamID)
+		if err != nil {
+			log.Printf("Error listing webhooks for team %s: %v", teamID, err)
+			drift++
+			continue
+		}
+
+		fmt.Printf("Team %s:\n", teamID)
+		found := false
+		for _, w := range webhooks {
+			state := webhookState(cfg, w)
+			if state == webhookOK {
+				if found {
+					state = "duplicate"
+				}
+				found = true
+			}
+			if state != webhookOK && state != webhookForeign {
+				drift++
+			}
+			fmt.Printf("  %-10s %s %s %s %s\n", state, w.ID, w.EventType, w.Status, w.Endpoint)
+		}
+		if _, _, ok := teamWebhook(cfg, webhooks); !ok {
+			drift++
+			fmt.Printf("  %-10s %s -> %s\n", "missing", webhook.EventFileComment, cfg.Webhook.Endpoint)
+		}
+	}
+
+	if drift > 0 {
+		fmt.Printf("%d difference(s) from config; run `webhooks create` for missing ones, `webhooks delete -id` for extra ones or ones with another passcode, resume paused ones in Figma\n", drift)
+	} else {
+		fmt.Println("Webhooks match the config")
+	}
+}
+
+// checkGeneratedPasscode не даёт сгенерировать новый passcode, пока
+// у команд есть подписки на наш endpoint со старым: новый passcode
+// не подошёл бы к ним, а сами подписки остались бы без изменений.
+func checkGeneratedPasscode(cfg *config.Config, teams map[string][]figma.Webhook) error {
+	for _, teamID := range cfg.Figma.TeamIDs {
+		for _, w := range teams[teamID] {
+			if webhookState(cfg, w) == webhookPasscode {
+				return fmt.Errorf("team %s already has webhook %s for %s with a passcode; put that passcode into webhook.passcode or delete the webhook before generating a new one",
+					teamID, w.ID, cfg.Webhook.Endpoint)
+			}
+		}
+	}
+	return nil
+}
+
+func createWebhooks(cfg *config.Config, figmaClient *figma.Client) {
+	if cfg.Webhook.Endpoint == "" {
+		log.Fatal("webhook.endpoint is not configured")
+	}
+	if len(cfg.Figma.TeamIDs) == 0 {
+		log.Fatal("figma.team_ids is not configured")
+	}
+	if cfg.Webhook.Passcode == placeholderPasscode {
+		log.Fatal(checkPasscode(cfg.Webhook.Passcode))
+	}
+
+	teams := make(map[string][]figma.Webhook)
+	for _, teamID := range cfg.Figma.TeamIDs {
+		webhooks, err := figmaClient.ListTeamWebhooks(teamID)
+		if err != nil {
+			log.Printf("Error listing webhooks for team %s: %v", teamID, err)
+			continue
+		}
+		teams[teamID] = webhooks
+	}
+
+	passcode := cfg.Webhook.Passcode
+	generated := false
+	if passcode == "" {
+		if err := checkGeneratedPasscode(cfg, teams); err != nil {
+			log.Fatal(err)
+		}
+		b := make([]byte, 24)
+		if _, err := rand.Read(b); err != nil {
+			log.Fatal(err)
+		}
+		passcode = hex.EncodeToString(b)
+		generated = true
+	}
+
+	created := 0
+	for _, teamID := range cfg.Figma.TeamIDs {
+		webhooks, ok := teams[teamID]
+		if !ok {
+			continue
+		}
+		if w, state, ok := teamWebhook(cfg, webhooks); ok {
+			switch state {
+			case webhookPaused:
+				fmt.Printf("Team %s: webhook %s is paused, resume it in Figma instead of creating another one\n", teamID, w.ID)
+			case webhookPasscode:
+				fmt.Printf("Team %s: webhook %s uses another passcode, delete it with `webhooks delete -id %s` and run create again\n", teamID, w.ID, w.ID)
+			default:
+				fmt.Printf("Team %s: webhook already registered\n", teamID)
+			}
+			continue
+		}
+
+		w, err := figmaClient.CreateWebhook(figma.CreateWebhookRequest{
+			EventType:   webhook.EventFileComment,
+			TeamID:      teamID,
+			Endpoint:    cfg.Webhook.Endpoint,
+			Passcode:    passcode,
+			Description: "figma-comment-reporter",
+		})
+		if err != nil {
+			log.Printf("Error creating webhook for team %s: %v", teamID, err)
+			continue
+		}
+		created++
+		fmt.Printf("Team %s: created webhook %s\n", teamID, w.ID)
+	}
+
+	if generated && created > 0 {
+		fmt.Printf("Generated passcode, set it as webhook.passcode in the config:\n%s\n", passcode)
+	}
+}
+
+// pingWebhook отправляет событие PING на webhook.endpoint, чтобы проверить,
+// что сервер доступен и passcode совпадает.
+func pingWebhook(cfg *config.Config) {
+	if cfg.Webhook.Endpoint == "" {
+		log.Fatal("webhook.endpoint is not configured")
+	}
+
+	payload, err := json.Marshal(webhook.Event{
+		EventType: webhook.EventPing,
+		Passcode:  cfg.Webhook.Passcode,
+		Timestamp: time.Now().UTC(),
+		WebhookID: "ping",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	resp, err := http.Post(cfg.Webhook.Endpoint, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		log.Fatalf("Ping failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("Ping failed: HTTP %d", resp.StatusCode)
+	}
+	fmt.Printf("Ping to %s succeeded\n", cfg.Webhook.Endpoint)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+)
+
+const testEndpoint = "https://reporter.example.com/figma/webhook"
+
+func testWebhookConfig(passcode string, teams ...string) *config.Config {
+	cfg := &config.Config{}
+	cfg.Figma.TeamIDs = teams
+	cfg.Webhook.Endpoint = testEndpoint
+	cfg.Webhook.Passcode = passcode
+	return cfg
+}
+
+func testWebhook(id, status, passcode string) figma.Webhook {
+	return figma.Webhook{ID: id, EventType: "FILE_COMMENT", Status: status, Passcode: passcode, Endpoint: testEndpoint}
+}
+
+func TestWebhookState(t *testing.T) {
+	tests := []struct {
+		name    string
+		webhook figma.Webhook
+		want    string
+	}{
+		{"ok", testWebhook("1", "ACTIVE", "secret"), webhookOK},
+		{"paused", testWebhook("1", "PAUSED", "secret"), webhookPaused},
+		{"other passcode", testWebhook("1", "ACTIVE", "old"), webhookPasscode},
+		{"paused with other passcode", testWebhook("1", "PAUSED", "old"), webhookPasscode},
+		{"other event", figma.Webhook{EventType: "FILE_UPDATE", Status: "ACTIVE", Passcode: "secret", Endpoint: testEndpoint}, webhookUnexpected},
+		{"other endpoint", figma.Webhook{EventType: "FILE_COMMENT", Status: "ACTIVE", Passcode: "secret", Endpoint: "https://other.example.com"}, webhookForeign},
+	}
+	cfg := testWebhookConfig("secret")
+	for _, tt := range tests {
+		if got := webhookState(cfg, tt.webhook); got != tt.want {
+			t.Errorf("%s: state = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTeamWebhook(t *testing.T) {
+	tests := []struct {
+		name     string
+		webhooks []figma.Webhook
+		wantID   string
+		want     string
+	}{
+		{name: "none"},
+		{name: "foreign only", webhooks: []figma.Webhook{{ID: "9", EventType: "FILE_COMMENT", Status: "ACTIVE", Endpoint: "https://other.example.com"}}},
+		{name: "ok wins", webhooks: []figma.Webhook{testWebhook("1", "PAUSED", "secret"), testWebhook("2", "ACTIVE", "secret")}, wantID: "2", want: webhookOK},
+		{name: "paused", webhooks: []figma.Webhook{testWebhook("1", "ACTIVE", "old"), testWebhook("2", "PAUSED", "secret")}, wantID: "2", want: webhookPaused},
+		{name: "other passcode", webhooks: []figma.Webhook{testWebhook("1", "ACTIVE", "old")}, wantID: "1", want: webhookPasscode},
+	}
+	cfg := testWebhookConfig("secret")
+	for _, tt := range tests {
+		w, state, ok := teamWebhook(cfg, tt.webhooks)
+		if ok != (tt.want != "") || w.ID != tt.wantID || state != tt.want {
+			t.Errorf("%s: got %s %q %v, want %s %q", tt.name, w.ID, state, ok, tt.wantID, tt.want)
+		}
+	}
+}
+
+func TestCheckGeneratedPasscode(t *testing.T) {
+	tests := []struct {
+		name    string
+		teams   map[string][]figma.Webhook
+		wantErr bool
+	}{
+		{name: "no webhooks", teams: map[string][]figma.Webhook{"t1": nil}},
+		{name: "foreign webhook", teams: map[string][]figma.Webhook{"t1": {{ID: "9", EventType: "FILE_COMMENT", Endpoint: "https://other.example.com", Passcode: "x"}}}},
+		{name: "webhook with old passcode", teams: map[string][]figma.Webhook{"t2": {testWebhook("1", "ACTIVE", "old")}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		err := checkGeneratedPasscode(testWebhookConfig("", "t1", "t2"), tt.teams)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCreateWebhooks(t *testing.T) {
+	teams := map[string][]figma.Webhook{
+		"ok":       {testWebhook("1", "ACTIVE", "secret")},
+		"paused":   {testWebhook("2", "PAUSED", "secret")},
+		"passcode": {testWebhook("3", "ACTIVE", "old")},
+		"empty":    nil,
+	}
+
+	var mu sync.Mutex
+	var created []figma.CreateWebhookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			var req figma.CreateWebhookRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			mu.Lock()
+			created = append(created, req)
+			mu.Unlock()
+			fmt.Fprint(w, `{"id":"new"}`)
+			return
+		}
+		team := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v2/teams/"), "/webhooks")
+		json.NewEncoder(w).Encode(map[string][]figma.Webhook{"webhooks": teams[team]})
+	}))
+	defer server.Close()
+
+	cfg := testWebhookConfig("secret", "ok", "paused", "passcode", "empty")
+	client := figma.NewClient("token")
+	client.BaseURL = server.URL
+	createWebhooks(cfg, client)
+
+	if len(created) != 1 || created[0].TeamID != "empty" || created[0].Passcode != "secret" {
+		t.Errorf("created = %+v, want one webhook for team empty", created)
+	}
+}
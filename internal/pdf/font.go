@@ -0,0 +1,143 @@
+package pdf
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+)
+
+// Шрифты DejaVu Sans покрывают латиницу, кириллицу, греческий и основные
+// знаки; лицензия — fonts/LICENSE-DejaVu.
+var (
+	//go:embed fonts/DejaVuSans.ttf
+	dejaVuSans []byte
+	//go:embed fonts/DejaVuSans-Bold.ttf
+	dejaVuSansBold []byte
+)
+
+// Font — один из встроенных шрифтов. В файл попадает подмножество
+// с глифами, которые использованы в документе.
+type Font int
+
+const (
+	Regular Font = iota
+	Bold
+)
+
+var faces = [...]*trueType{
+	Regular: mustParseTrueType("DejaVuSans", dejaVuSans),
+	Bold:    mustParseTrueType("DejaVuSans-Bold", dejaVuSansBold),
+}
+
+func mustParseTrueType(name string, data []byte) *trueType {
+	f, err := parseTrueType(name, data)
+	if err == nil && len(f.glyphs) == 0 {
+		err = errors.New("no Unicode cmap")
+	}
+	if err != nil {
+		panic(fmt.Sprintf("pdf: embedded font %s: %v", name, err))
+	}
+	return f
+}
+
+func (f Font) face() *trueType {
+	return faces[f]
+}
+
+func (f Font) resourceName() string {
+	if f == Bold {
+		return "F2"
+	}
+	return "F1"
+}
+
+// glyph возвращает глиф для r; символы, которых нет в шрифте,
+// выводятся как "?".
+func (f Font) glyph(r rune) (uint16, rune) {
+	if gid, ok := f.face().glyph(r); ok {
+		return gid, r
+	}
+	gid, _ := f.face().glyph('?')
+	return gid, '?'
+}
+
+// width возвращает ширину символа в 1/1000 кегля по таблице hmtx.
+func (f Font) width(r rune) int {
+	gid, _ := f.glyph(r)
+	return f.face().advance(gid)
+}
+
+// glyphSet — использованные глифы шрифта и символы, которые они выводят.
+type glyphSet map[uint16]rune
+
+func (s glyphSet) sorted() []uint16 {
+	gids := make([]uint16, 0, len(s))
+	for gid := range s {
+		gids = append(gids, gid)
+	}
+	sort.Slice(gids, func(i, j int) bool { return gids[i] < gids[j] })
+	return gids
+}
+
+// subsetName добавляет к имени шрифта метку подмножества из шести
+// заглавных букв, как требует PDF.
+func subsetName(name string, gids []uint16) string {
+	h := fnv.New32a()
+	for _, gid := range gids {
+		h.Write([]byte{byte(gid >> 8), byte(gid)})
+	}
+	sum := h.Sum32()
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = byte('A' + sum%26)
+		sum /= 26
+	}
+	return string(tag) + "+" + name
+}
+
+// widths строит массив /W: подряд идущие глифы записываются одной группой.
+func widths(face *trueType, gids []uint16) string {
+	var b strings.Builder
+	for i := 0; i < len(gids); {
+		j := i + 1
+		for j < len(gids) && gids[j] == gids[j-1]+1 {
+			j++
+		}
+		fmt.Fprintf(&b, "%d [", gids[i])
+		for k := i; k < j; k++ {
+			if k > i {
+				b.WriteByte(' ')
+			}
+			fmt.Fprint(&b, face.advance(gids[k]))
+		}
+		b.WriteString("] ")
+		i = j
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// toUnicode строит CMap, по которой просмотрщики копируют и ищут текст.
+func toUnicode(glyphs glyphSet) string {
+	var b strings.Builder
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	b.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	b.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	b.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	// В одном блоке bfchar допускается не больше 100 записей
+	gids := glyphs.sorted()
+	for start := 0; start < len(gids); start += 100 {
+		end := min(start+100, len(gids))
+		fmt.Fprintf(&b, "%d beginbfchar\n", end-start)
+		for _, gid := range gids[start:end] {
+			fmt.Fprintf(&b, "<%04X> <%s>\n", gid, utf16Hex(glyphs[gid]))
+		}
+		b.WriteString("endbfchar\n")
+	}
+
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.String()
+}
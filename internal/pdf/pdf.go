@@ -0,0 +1,355 @@
+// Package pdf — минимальный генератор PDF для текстовых отчётов: страницы
+// A4, встроенный шрифт DejaVu Sans (обычный и жирный), линии и ссылки.
+// Внешних зависимостей нет.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf16"
+)
+
+// Размер страницы A4 в пунктах.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+type link struct {
+	x, y, w, h float64
+	url        string
+}
+
+type page struct {
+	content bytes.Buffer
+	links   []link
+}
+
+// Document накапливает страницы. Координаты отсчитываются от левого
+// верхнего угла страницы, y растёт вниз.
+type Document struct {
+	Title   string
+	Author  string
+	Created time.Time
+
+	pages []*page
+	// Использованные глифы каждого шрифта
+	glyphs [len(faces)]glyphSet
+}
+
+func New() *Document {
+	return &Document{Created: time.Now()}
+}
+
+// AddPage начинает новую страницу; дальнейший вывод идёт на неё.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &page{})
+}
+
+// PageCount возвращает число страниц.
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+func (d *Document) current() *page {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// Text выводит строку; y — положение базовой линии. gray задаёт цвет
+// от 0 (чёрный) до 1 (белый).
+func (d *Document) Text(x, y float64, font Font, size, gray float64, s string) {
+	if s == "" {
+		return
+	}
+	p := d.current()
+	fmt.Fprintf(&p.content, "BT %.3g g /%s %.4g Tf %.2f %.2f Td <%X> Tj ET\n",
+		gray, font.resourceName(), size, x, PageHeight-y, d.encode(font, s))
+}
+
+// encode переводит строку в двухбайтовые номера глифов и запоминает их
+// для подмножества шрифта.
+func (d *Document) encode(font Font, s string) []byte {
+	if d.glyphs[font] == nil {
+		d.glyphs[font] = make(glyphSet)
+	}
+	out := make([]byte, 0, 2*len(s))
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			r = ' '
+		}
+		gid, shown := font.glyph(r)
+		if _, ok := d.glyphs[font][gid]; !ok {
+			d.glyphs[font][gid] = shown
+		}
+		out = append(out, byte(gid>>8), byte(gid))
+	}
+	return out
+}
+
+// Line рисует горизонтальную или любую другую линию толщиной width.
+func (d *Document) Line(x1, y1, x2, y2, width, gray float64) {
+	p := d.current()
+	fmt.Fprintf(&p.content, "%.3g G %.2f w %.2f %.2f m %.2f %.2f l S\n",
+		gray, width, x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// Link делает прямоугольник с левым верхним углом (x, y) ссылкой на url.
+func (d *Document) Link(x, y, w, h float64, url string) {
+	p := d.current()
+	p.links = append(p.links, link{x: x, y: y, w: w, h: h, url: url})
+}
+
+// TextWidth возвращает ширину строки в пунктах.
+func TextWidth(font Font, size float64, s string) float64 {
+	total := 0
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			r = ' '
+		}
+		total += font.width(r)
+	}
+	return float64(total) * size / 1000
+}
+
+// Wrap разбивает текст на строки не шире width. Переводы строк
+// сохраняются, слова длиннее строки переносятся по символам.
+func Wrap(font Font, size, width float64, text string) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		line := ""
+		for _, word := range words {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if TextWidth(font, size, candidate) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			for TextWidth(font, size, word) > width {
+				cut := fitRunes(font, size, width, word)
+				lines = append(lines, word[:cut])
+				word = word[cut:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fitRunes возвращает длину в байтах самого длинного префикса s, который
+// помещается в width; хотя бы один символ берётся всегда.
+func fitRunes(font Font, size, width float64, s string) int {
+	end := 0
+	for i, r := range s {
+		if i > 0 && TextWidth(font, size, s[:i+len(string(r))]) > width {
+			break
+		}
+		end = i + len(string(r))
+	}
+	return end
+}
+
+// Bytes собирает файл PDF.
+func (d *Document) Bytes() ([]byte, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	w := &writer{}
+	w.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Номера объектов: 1 — каталог, 2 — дерево страниц, 3 — сведения
+	// о документе, далее по fontObjects на шрифт и страницы.
+	const (
+		catalogID = iota + 1
+		pagesID
+		infoID
+		firstFontID
+	)
+	fontIDs := make([]int, len(faces))
+	for i := range fontIDs {
+		fontIDs[i] = firstFontID + i*fontObjects
+	}
+
+	pageIDs := make([]int, len(d.pages))
+	next := firstFontID + len(faces)*fontObjects
+	for i, p := range d.pages {
+		pageIDs[i] = next
+		next += 2 + len(p.links)
+	}
+
+	w.object(catalogID, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesID))
+
+	kids := make([]string, len(pageIDs))
+	for i, id := range pageIDs {
+		kids[i] = fmt.Sprintf("%d 0 R", id)
+	}
+	w.object(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %.2f %.2f] >>",
+		strings.Join(kids, " "), len(pageIDs), PageWidth, PageHeight))
+
+	w.object(infoID, fmt.Sprintf("<< /Title %s /Author %s /Producer %s /CreationDate %s >>",
+		textString(d.Title), textString(d.Author), literalString("figma-comment-reporter"),
+		literalString(d.Created.Format("D:20060102150405Z07'00'"))))
+
+	fonts := make([]string, len(faces))
+	for i := range faces {
+		font := Font(i)
+		if err := w.font(fontIDs[i], font, d.glyphs[font]); err != nil {
+			return nil, err
+		}
+		fonts[i] = fmt.Sprintf("/%s %d 0 R", font.resourceName(), fontIDs[i])
+	}
+
+	for i, p := range d.pages {
+		id := pageIDs[i]
+		contentID := id + 1
+
+		annots := ""
+		if len(p.links) > 0 {
+			refs := make([]string, len(p.links))
+			for j := range p.links {
+				refs[j] = fmt.Sprintf("%d 0 R", contentID+1+j)
+			}
+			annots = fmt.Sprintf(" /Annots [%s]", strings.Join(refs, " "))
+		}
+		w.object(id, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /Resources << /Font << %s >> >> /Contents %d 0 R%s >>",
+			pagesID, strings.Join(fonts, " "), contentID, annots))
+
+		if err := w.stream(contentID, "", p.content.Bytes()); err != nil {
+			return nil, err
+		}
+
+		for j, l := range p.links {
+			w.object(contentID+1+j, fmt.Sprintf("<< /Type /Annot /Subtype /Link /Rect [%.2f %.2f %.2f %.2f] /Border [0 0 0] /A << /S /URI /URI %s >> >>",
+				l.x, PageHeight-l.y-l.h, l.x+l.w, PageHeight-l.y, literalString(l.url)))
+		}
+	}
+
+	w.finish(catalogID, infoID)
+	return w.buf.Bytes(), nil
+}
+
+func utf16Hex(r rune) string {
+	var b strings.Builder
+	for _, u := range utf16.Encode([]rune{r}) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	return b.String()
+}
+
+// textString кодирует строку сведений о документе в UTF-16BE с BOM.
+func textString(s string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+var literalReplacer = strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`)
+
+func literalString(s string) string {
+	return "(" + literalReplacer.Replace(s) + ")"
+}
+
+// writer записывает объекты и запоминает их смещения для таблицы xref.
+type writer struct {
+	buf     bytes.Buffer
+	offsets map[int]int
+}
+
+func (w *writer) object(id int, body string) {
+	if w.offsets == nil {
+		w.offsets = make(map[int]int)
+	}
+	w.offsets[id] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+// stream записывает поток, сжатый Flate; dict — дополнительные ключи словаря.
+func (w *writer) stream(id int, dict string, data []byte) error {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	if w.offsets == nil {
+		w.offsets = make(map[int]int)
+	}
+	w.offsets[id] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< /Length %d /Filter /FlateDecode%s >>\nstream\n", id, compressed.Len(), dict)
+	w.buf.Write(compressed.Bytes())
+	w.buf.WriteString("\nendstream\nendobj\n")
+	return nil
+}
+
+// Шрифт занимает пять объектов: Type0, CIDFontType2, FontDescriptor,
+// файл шрифта и ToUnicode.
+const fontObjects = 5
+
+// font записывает шрифт Type0 с кодировкой Identity-H: коды в тексте —
+// номера глифов встроенного подмножества.
+func (w *writer) font(id int, font Font, glyphs glyphSet) error {
+	face := font.face()
+	gids := glyphs.sorted()
+	name := subsetName(face.name, gids)
+
+	w.object(id, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		name, id+1, id+4))
+	w.object(id+1, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /DW %d /W [%s] >>",
+		name, id+2, face.advance(0), widths(face, gids)))
+
+	stemV := 80
+	if font == Bold {
+		stemV = 140
+	}
+	w.object(id+2, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle %g /Ascent %d /Descent %d /CapHeight %d /StemV %d /FontFile2 %d 0 R >>",
+		name, face.scale(face.bbox[0]), face.scale(face.bbox[1]), face.scale(face.bbox[2]), face.scale(face.bbox[3]),
+		face.italicAngle, face.scale(face.ascent), face.scale(face.descent), face.scale(face.capHeight), stemV, id+3))
+
+	data := face.subset(glyphs)
+	if err := w.stream(id+3, fmt.Sprintf(" /Length1 %d", len(data)), data); err != nil {
+		return err
+	}
+	return w.stream(id+4, "", []byte(toUnicode(glyphs)))
+}
+
+func (w *writer) finish(rootID, infoID int) {
+	count := 0
+	for id := range w.offsets {
+		count = max(count, id)
+	}
+
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", count+1)
+	for id := 1; id <= count; id++ {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", w.offsets[id])
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		count+1, rootID, infoID, xref)
+}
@@ -0,0 +1,252 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type pdfStream struct {
+	dict string
+	data []byte
+}
+
+var streamHeader = regexp.MustCompile(`(?m)^(\d+) 0 obj\n<< /Length (\d+) /Filter /FlateDecode([^>]*)>>\nstream\n`)
+
+// streams распаковывает все потоки файла по номерам объектов.
+func streams(t *testing.T, file []byte) map[int]pdfStream {
+	t.Helper()
+	result := make(map[int]pdfStream)
+	for _, m := range streamHeader.FindAllSubmatchIndex(file, -1) {
+		id, _ := strconv.Atoi(string(file[m[2]:m[3]]))
+		length, _ := strconv.Atoi(string(file[m[4]:m[5]]))
+		zr, err := zlib.NewReader(bytes.NewReader(file[m[1] : m[1]+length]))
+		if err != nil {
+			t.Fatalf("object %d: %v", id, err)
+		}
+		data, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("object %d: %v", id, err)
+		}
+		result[id] = pdfStream{dict: string(file[m[6]:m[7]]), data: data}
+	}
+	return result
+}
+
+func testDocument(t *testing.T) ([]byte, map[int]pdfStream) {
+	t.Helper()
+	doc := New()
+	doc.Title = "Отчёт"
+	doc.Text(50, 50, Bold, 16, 0, "Заголовок")
+	doc.Text(50, 80, Regular, 10, 0, "Fix the button — Поправить отступ № 5 ✓ 漢")
+	doc.Link(50, 70, 100, 12, "https://www.figma.com/file/KEY?node-id=1-3")
+	doc.AddPage()
+	doc.Text(50, 50, Regular, 10, 0.3, "Ёлка\tи ёж")
+
+	file, err := doc.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file, streams(t, file)
+}
+
+func TestXref(t *testing.T) {
+	file, _ := testDocument(t)
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(file)
+	if m == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(file[xref:], []byte("xref\n0 ")) {
+		t.Fatalf("startxref %d does not point to xref", xref)
+	}
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(file[xref:], -1)
+	if len(entries) == 0 {
+		t.Fatal("empty xref")
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		want := fmt.Sprintf("%d 0 obj\n", i+1)
+		if !bytes.HasPrefix(file[offset:], []byte(want)) {
+			t.Errorf("object %d: offset %d points to %q", i+1, offset, file[offset:min(offset+20, len(file))])
+		}
+	}
+}
+
+func TestEmbeddedFonts(t *testing.T) {
+	file, objects := testDocument(t)
+
+	for _, want := range []string{"/Subtype /Type0", "/Encoding /Identity-H", "/Subtype /CIDFontType2", "/CIDToGIDMap /Identity", "+DejaVuSans ", "+DejaVuSans-Bold "} {
+		if !bytes.Contains(file, []byte(want)) {
+			t.Errorf("no %q in file", want)
+		}
+	}
+
+	var fonts []*trueType
+	var cmaps []string
+	for _, s := range objects {
+		switch {
+		case strings.Contains(s.dict, "/Length1"):
+			font, err := parseTrueType("subset", s.data)
+			if err != nil {
+				t.Fatalf("embedded font: %v", err)
+			}
+			fonts = append(fonts, font)
+		case bytes.Contains(s.data, []byte("begincmap")):
+			cmaps = append(cmaps, string(s.data))
+		}
+	}
+	if len(fonts) != 2 || len(cmaps) != 2 {
+		t.Fatalf("got %d fonts and %d ToUnicode maps, want 2 and 2", len(fonts), len(cmaps))
+	}
+	if len(fonts[0].glyphs) > 0 {
+		t.Error("subset keeps cmap")
+	}
+
+	// Глиф есть в подмножестве, если у него остались контуры
+	hasGlyph := func(font Font, r rune) bool {
+		gid, _ := font.glyph(r)
+		for _, f := range fonts {
+			if len(f.glyphData(int(gid))) > 0 {
+				return true
+			}
+		}
+		return false
+	}
+	tests := []struct {
+		font Font
+		r    rune
+		want bool
+	}{
+		{Regular, 'F', true},
+		{Regular, 'П', true},
+		{Regular, '№', true},
+		{Regular, 'ё', true},
+		{Regular, '?', true},
+		{Bold, 'З', true},
+		{Regular, 'Z', false},
+		{Regular, 'Щ', false},
+	}
+	for _, tt := range tests {
+		if got := hasGlyph(tt.font, tt.r); got != tt.want {
+			t.Errorf("glyph %q in subset = %v, want %v", tt.r, got, tt.want)
+		}
+	}
+
+	all := strings.Join(cmaps, "")
+	for _, r := range []rune{'F', 'П', '—', '✓', 'Ё', 'З', '?'} {
+		gid, _ := Regular.glyph(r)
+		if r == 'З' {
+			gid, _ = Bold.glyph(r)
+		}
+		want := fmt.Sprintf("<%04X> <%s>", gid, utf16Hex(r))
+		if !strings.Contains(all, want) {
+			t.Errorf("ToUnicode has no %s for %q", want, r)
+		}
+	}
+	// Символ, которого нет в шрифте, выводится глифом "?"
+	if gid, _ := Regular.glyph('漢'); strings.Contains(all, fmt.Sprintf("<%s>\n", utf16Hex('漢'))) || gid != mustGlyph(t, '?') {
+		t.Error("missing rune is not replaced with ?")
+	}
+}
+
+func mustGlyph(t *testing.T, r rune) uint16 {
+	t.Helper()
+	gid, ok := Regular.face().glyph(r)
+	if !ok {
+		t.Fatalf("no glyph for %q", r)
+	}
+	return gid
+}
+
+func TestSubsetComposite(t *testing.T) {
+	face := Regular.face()
+	gid := mustGlyph(t, 'Ё')
+	parts := face.components(int(gid))
+	if len(parts) == 0 {
+		t.Skip("Ё is not a composite glyph")
+	}
+
+	subset, err := parseTrueType("subset", face.subset(glyphSet{gid: 'Ё'}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, part := range append(parts, int(gid), 0) {
+		if len(subset.glyphData(part)) == 0 {
+			t.Errorf("glyph %d dropped from subset", part)
+		}
+	}
+	if subset.numGlyphs != face.numGlyphs {
+		t.Errorf("numGlyphs = %d, want %d", subset.numGlyphs, face.numGlyphs)
+	}
+	if sum := checksum(face.subset(glyphSet{gid: 'Ё'})); sum != 0xB1B0AFBA {
+		t.Errorf("font checksum = %#x, want 0xB1B0AFBA", sum)
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		font Font
+		s    string
+		want float64
+	}{
+		// Ширины DejaVu Sans из hmtx при unitsPerEm 2048
+		{Regular, "A", 6.84},
+		{Regular, "AA", 13.68},
+		{Regular, "a\tb", TextWidth(Regular, 10, "a b")},
+		{Regular, "漢", TextWidth(Regular, 10, "?")},
+		{Bold, "A", 7.74},
+	}
+	for _, tt := range tests {
+		if got := TextWidth(tt.font, 10, tt.s); fmt.Sprintf("%.2f", got) != fmt.Sprintf("%.2f", tt.want) {
+			t.Errorf("TextWidth(%d, %q) = %.2f, want %.2f", tt.font, tt.s, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"Ж", "ы", "Поправить", "№"} {
+		regular, bold := TextWidth(Regular, 10, s), TextWidth(Bold, 10, s)
+		if regular <= 0 || bold <= regular {
+			t.Errorf("%q: regular %.2f, bold %.2f", s, regular, bold)
+		}
+	}
+	if TextWidth(Regular, 10, "Ш") <= TextWidth(Regular, 10, "г") {
+		t.Error("Ш is not wider than г")
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		width float64
+		text  string
+		want  []string
+	}{
+		{"fits", 200, "Поправить отступ", []string{"Поправить отступ"}},
+		{"words", 60, "Поправить отступ у кнопки", []string{"Поправить", "отступ у", "кнопки"}},
+		{"newlines", 200, "a\r\nb\n\nc", []string{"a", "b", "", "c"}},
+		{"long word", 30, "Щщщщщщщщ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := Wrap(Regular, 10, tt.width, tt.text)
+			if tt.want != nil && strings.Join(lines, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("lines = %q, want %q", lines, tt.want)
+			}
+			if strings.Join(lines, "") != strings.Join(strings.Fields(strings.ReplaceAll(tt.text, "\n", " ")), "") && tt.want == nil {
+				t.Errorf("text lost: %q", lines)
+			}
+			for _, line := range lines {
+				if w := TextWidth(Regular, 10, line); w > tt.width && len([]rune(line)) > 1 {
+					t.Errorf("line %q is %.2f wide, limit %.2f", line, w, tt.width)
+				}
+			}
+		})
+	}
+}
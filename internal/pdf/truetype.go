@@ -0,0 +1,394 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// trueType — разобранный шрифт TrueType: метрики, таблица символов
+// и смещения глифов, по которым собирается встраиваемое подмножество.
+type trueType struct {
+	// PostScript-имя шрифта
+	name   string
+	tables map[string][]byte
+
+	unitsPerEm  int
+	bbox        [4]int
+	ascent      int
+	descent     int
+	capHeight   int
+	italicAngle float64
+
+	numGlyphs int
+	// Ширины глифов в единицах шрифта
+	advances []int
+	glyphs   map[rune]uint16
+	// Смещения глифов в таблице glyf, numGlyphs+1 значений
+	loca []int
+}
+
+// Таблицы, которые PDF требует во встроенном шрифте CIDFontType2.
+// Таблица cmap не нужна: коды в тексте — это номера глифов.
+var subsetTables = []string{"cvt ", "fpgm", "glyf", "head", "hhea", "hmtx", "loca", "maxp", "prep"}
+
+func parseTrueType(name string, data []byte) (*trueType, error) {
+	if len(data) < 12 {
+		return nil, errors.New("truetype: file too short")
+	}
+	f := &trueType{name: name, tables: make(map[string][]byte)}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+16*numTables {
+		return nil, errors.New("truetype: truncated table directory")
+	}
+	for i := 0; i < numTables; i++ {
+		entry := data[12+16*i:]
+		tag := string(entry[:4])
+		offset := int(binary.BigEndian.Uint32(entry[8:]))
+		length := int(binary.BigEndian.Uint32(entry[12:]))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil, fmt.Errorf("truetype: table %q out of bounds", tag)
+		}
+		f.tables[tag] = data[offset : offset+length]
+	}
+
+	minLength := map[string]int{"head": 54, "hhea": 36, "maxp": 6, "hmtx": 4, "loca": 0, "glyf": 0}
+	for tag, n := range minLength {
+		if data, ok := f.tables[tag]; !ok || len(data) < n {
+			return nil, fmt.Errorf("truetype: missing or short table %q", tag)
+		}
+	}
+
+	head := f.tables["head"]
+	f.unitsPerEm = int(binary.BigEndian.Uint16(head[18:]))
+	if f.unitsPerEm == 0 {
+		return nil, errors.New("truetype: zero unitsPerEm")
+	}
+	for i := range f.bbox {
+		f.bbox[i] = int(int16(binary.BigEndian.Uint16(head[36+2*i:])))
+	}
+	longLoca := binary.BigEndian.Uint16(head[50:]) == 1
+
+	hhea := f.tables["hhea"]
+	f.ascent = int(int16(binary.BigEndian.Uint16(hhea[4:])))
+	f.descent = int(int16(binary.BigEndian.Uint16(hhea[6:])))
+	f.capHeight = f.ascent
+	if os2 := f.tables["OS/2"]; len(os2) >= 90 && binary.BigEndian.Uint16(os2) >= 2 {
+		f.capHeight = int(int16(binary.BigEndian.Uint16(os2[88:])))
+	}
+	if post := f.tables["post"]; len(post) >= 8 {
+		f.italicAngle = float64(int32(binary.BigEndian.Uint32(post[4:]))) / 65536
+	}
+
+	f.numGlyphs = int(binary.BigEndian.Uint16(f.tables["maxp"][4:]))
+
+	// Глифы после numberOfHMetrics наследуют последнюю ширину
+	metrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	hmtx := f.tables["hmtx"]
+	if metrics == 0 || metrics > f.numGlyphs || len(hmtx) < 4*metrics {
+		return nil, errors.New("truetype: invalid hmtx")
+	}
+	f.advances = make([]int, f.numGlyphs)
+	for i := range f.advances {
+		f.advances[i] = int(binary.BigEndian.Uint16(hmtx[4*min(i, metrics-1):]))
+	}
+
+	loca := f.tables["loca"]
+	f.loca = make([]int, f.numGlyphs+1)
+	for i := range f.loca {
+		if longLoca {
+			if len(loca) < 4*(i+1) {
+				return nil, errors.New("truetype: truncated loca")
+			}
+			f.loca[i] = int(binary.BigEndian.Uint32(loca[4*i:]))
+		} else {
+			if len(loca) < 2*(i+1) {
+				return nil, errors.New("truetype: truncated loca")
+			}
+			f.loca[i] = 2 * int(binary.BigEndian.Uint16(loca[2*i:]))
+		}
+		if f.loca[i] > len(f.tables["glyf"]) || i > 0 && f.loca[i] < f.loca[i-1] {
+			return nil, errors.New("truetype: invalid loca")
+		}
+	}
+
+	// Во встроенном подмножестве таблицы символов нет
+	f.glyphs = make(map[rune]uint16)
+	if cmap, ok := f.tables["cmap"]; ok {
+		glyphs, err := parseCmap(cmap, f.numGlyphs)
+		if err != nil {
+			return nil, err
+		}
+		f.glyphs = glyphs
+	}
+	return f, nil
+}
+
+// parseCmap читает таблицу символов Unicode: формат 12 из записи (3, 10),
+// если она есть, иначе формат 4 из BMP.
+func parseCmap(cmap []byte, numGlyphs int) (map[rune]uint16, error) {
+	var format4, format12 []byte
+	if len(cmap) < 4 {
+		return nil, errors.New("truetype: truncated cmap")
+	}
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+	if len(cmap) < 4+8*numTables {
+		return nil, errors.New("truetype: truncated cmap")
+	}
+	for i := 0; i < numTables; i++ {
+		record := cmap[4+8*i:]
+		platform := binary.BigEndian.Uint16(record)
+		encoding := binary.BigEndian.Uint16(record[2:])
+		offset := int(binary.BigEndian.Uint32(record[4:]))
+		if platform != 0 && !(platform == 3 && (encoding == 1 || encoding == 10)) {
+			continue
+		}
+		if offset+2 > len(cmap) {
+			return nil, errors.New("truetype: cmap subtable out of bounds")
+		}
+		switch binary.BigEndian.Uint16(cmap[offset:]) {
+		case 4:
+			format4 = cmap[offset:]
+		case 12:
+			format12 = cmap[offset:]
+		}
+	}
+
+	glyphs := make(map[rune]uint16)
+	add := func(r rune, gid int) {
+		if gid > 0 && gid < numGlyphs {
+			glyphs[r] = uint16(gid)
+		}
+	}
+
+	switch {
+	case format12 != nil:
+		if len(format12) < 16 {
+			return nil, errors.New("truetype: truncated cmap format 12")
+		}
+		groups := int(binary.BigEndian.Uint32(format12[12:]))
+		if len(format12) < 16+12*groups {
+			return nil, errors.New("truetype: truncated cmap format 12")
+		}
+		for i := 0; i < groups; i++ {
+			group := format12[16+12*i:]
+			start := binary.BigEndian.Uint32(group)
+			end := binary.BigEndian.Uint32(group[4:])
+			gid := int(binary.BigEndian.Uint32(group[8:]))
+			if end < start || end > 0x10FFFF || gid >= numGlyphs {
+				continue
+			}
+			end = min(end, start+uint32(numGlyphs-1-gid))
+			for c := start; c <= end; c++ {
+				add(rune(c), gid+int(c-start))
+			}
+		}
+	case format4 != nil:
+		if len(format4) < 14 {
+			return nil, errors.New("truetype: truncated cmap format 4")
+		}
+		segments := int(binary.BigEndian.Uint16(format4[6:])) / 2
+		if len(format4) < 16+8*segments {
+			return nil, errors.New("truetype: truncated cmap format 4")
+		}
+		ends := format4[14:]
+		starts := format4[16+2*segments:]
+		deltas := format4[16+4*segments:]
+		rangeOffsets := format4[16+6*segments:]
+		for i := 0; i < segments; i++ {
+			start := int(binary.BigEndian.Uint16(starts[2*i:]))
+			end := int(binary.BigEndian.Uint16(ends[2*i:]))
+			delta := int(binary.BigEndian.Uint16(deltas[2*i:]))
+			rangeOffset := int(binary.BigEndian.Uint16(rangeOffsets[2*i:]))
+			for c := start; c <= end && c != 0xFFFF; c++ {
+				if rangeOffset == 0 {
+					add(rune(c), (c+delta)&0xFFFF)
+					continue
+				}
+				// Смещение отсчитывается от самого элемента idRangeOffset
+				at := 16 + 6*segments + 2*i + rangeOffset + 2*(c-start)
+				if at+2 > len(format4) {
+					break
+				}
+				if gid := int(binary.BigEndian.Uint16(format4[at:])); gid != 0 {
+					add(rune(c), (gid+delta)&0xFFFF)
+				}
+			}
+		}
+	default:
+		return nil, errors.New("truetype: no Unicode cmap")
+	}
+	return glyphs, nil
+}
+
+// glyph возвращает номер глифа для r или false, если его нет в шрифте.
+func (f *trueType) glyph(r rune) (uint16, bool) {
+	gid, ok := f.glyphs[r]
+	return gid, ok
+}
+
+// advance возвращает ширину глифа в 1/1000 кегля, как её ждёт PDF.
+func (f *trueType) advance(gid uint16) int {
+	return (f.advances[gid]*1000 + f.unitsPerEm/2) / f.unitsPerEm
+}
+
+// scale переводит единицы шрифта в 1/1000 кегля.
+func (f *trueType) scale(v int) int {
+	return v * 1000 / f.unitsPerEm
+}
+
+func (f *trueType) glyphData(gid int) []byte {
+	return f.tables["glyf"][f.loca[gid]:f.loca[gid+1]]
+}
+
+// components возвращает глифы, из которых собран составной глиф.
+func (f *trueType) components(gid int) []int {
+	data := f.glyphData(gid)
+	if len(data) < 10 || int16(binary.BigEndian.Uint16(data)) >= 0 {
+		return nil
+	}
+	const (
+		argsAreWords   = 0x0001
+		haveScale      = 0x0008
+		moreComponents = 0x0020
+		haveXYScale    = 0x0040
+		haveTwoByTwo   = 0x0080
+	)
+	var gids []int
+	for at := 10; at+4 <= len(data); {
+		flags := binary.BigEndian.Uint16(data[at:])
+		gids = append(gids, int(binary.BigEndian.Uint16(data[at+2:])))
+		at += 4
+		if flags&argsAreWords != 0 {
+			at += 4
+		} else {
+			at += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			at += 2
+		case flags&haveXYScale != 0:
+			at += 4
+		case flags&haveTwoByTwo != 0:
+			at += 8
+		}
+		if flags&moreComponents == 0 {
+			break
+		}
+	}
+	return gids
+}
+
+// subset собирает шрифт только с глифами used, глиф 0 и части составных
+// глифов. Номера глифов не меняются: у остальных пустые контуры
+// и нулевые метрики, поэтому лишние таблицы хорошо сжимаются.
+func (f *trueType) subset(used map[uint16]rune) []byte {
+	keep := make([]bool, f.numGlyphs)
+	queue := []int{0}
+	for gid := range used {
+		queue = append(queue, int(gid))
+	}
+	for len(queue) > 0 {
+		gid := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if gid >= f.numGlyphs || keep[gid] {
+			continue
+		}
+		keep[gid] = true
+		queue = append(queue, f.components(gid)...)
+	}
+
+	var glyf []byte
+	loca := make([]byte, 4*(f.numGlyphs+1))
+	for gid := 0; gid < f.numGlyphs; gid++ {
+		binary.BigEndian.PutUint32(loca[4*gid:], uint32(len(glyf)))
+		if keep[gid] {
+			glyf = append(glyf, f.glyphData(gid)...)
+			for len(glyf)%4 != 0 {
+				glyf = append(glyf, 0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(loca[4*f.numGlyphs:], uint32(len(glyf)))
+
+	hmtx := append([]byte(nil), f.tables["hmtx"]...)
+	metrics := int(binary.BigEndian.Uint16(f.tables["hhea"][34:]))
+	for gid := 0; gid < f.numGlyphs; gid++ {
+		if keep[gid] {
+			continue
+		}
+		if gid < metrics {
+			clear(hmtx[4*gid : 4*gid+4])
+		} else if at := 4*metrics + 2*(gid-metrics); at+2 <= len(hmtx) {
+			clear(hmtx[at : at+2])
+		}
+	}
+
+	head := append([]byte(nil), f.tables["head"]...)
+	// checkSumAdjustment считается заново, loca пишется в длинном формате
+	clear(head[8:12])
+	binary.BigEndian.PutUint16(head[50:], 1)
+
+	tables := map[string][]byte{"glyf": glyf, "loca": loca, "hmtx": hmtx, "head": head}
+	var tags []string
+	for _, tag := range subsetTables {
+		if _, ok := tables[tag]; !ok {
+			if data, ok := f.tables[tag]; ok {
+				tables[tag] = data
+			}
+		}
+		if _, ok := tables[tag]; ok {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+
+	return writeTrueType(tags, tables)
+}
+
+// writeTrueType собирает файл шрифта из таблиц в порядке tags.
+func writeTrueType(tags []string, tables map[string][]byte) []byte {
+	searchRange, entrySelector := 1, 0
+	for searchRange*2 <= len(tags) {
+		searchRange *= 2
+		entrySelector++
+	}
+
+	out := make([]byte, 12+16*len(tags))
+	binary.BigEndian.PutUint32(out, 0x00010000)
+	binary.BigEndian.PutUint16(out[4:], uint16(len(tags)))
+	binary.BigEndian.PutUint16(out[6:], uint16(16*searchRange))
+	binary.BigEndian.PutUint16(out[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(out[10:], uint16(16*(len(tags)-searchRange)))
+
+	headAt := 0
+	for i, tag := range tags {
+		data := tables[tag]
+		if tag == "head" {
+			headAt = len(out)
+		}
+		entry := out[12+16*i:]
+		copy(entry, tag)
+		binary.BigEndian.PutUint32(entry[4:], checksum(data))
+		binary.BigEndian.PutUint32(entry[8:], uint32(len(out)))
+		binary.BigEndian.PutUint32(entry[12:], uint32(len(data)))
+		out = append(out, data...)
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+	}
+	binary.BigEndian.PutUint32(out[headAt+8:], 0xB1B0AFBA-checksum(out))
+	return out
+}
+
+// checksum — сумма 32-битных слов таблицы, дополненной нулями.
+func checksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
@@ -1 +1,39 @@
 package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MatchGlob сопоставляет строку с шаблоном, где * — любая последовательность
+// символов (включая "/"), а ? — один символ. Регистр не учитывается.
+func MatchGlob(pattern, name string) bool {
+	var expr strings.Builder
+	expr.WriteString("(?is)^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return false
+	}
+	return re.MatchString(name)
+}
+
+func MatchAnyGlob(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if MatchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// Cache хранит ответы Figma на диске. Запись действительна, пока не
+// изменилась версия, с которой она сохранена, и не истёк TTL.
+type Cache struct {
+	Dir string
+	TTL time.Duration
+}
+
+type record struct {
+	Version  string          `json:"version"`
+	StoredAt time.Time       `json:"stored_at"`
+	Data     json.RawMessage `json:"data"`
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+func New(dir string, ttl time.Duration) *Cache {
+	return &Cache{Dir: dir, TTL: ttl}
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, unsafeChars.ReplaceAllString(key, "_")+".json")
+}
+
+// Load читает запись в v. false означает промах: записи нет, она устарела
+// или сохранена для другой версии.
+func (c *Cache) Load(key, version string, v interface{}) (bool, error) {
+	data, err := os.ReadFile(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var rec record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return false, err
+	}
+	if rec.Version != version {
+		return false, nil
+	}
+	if c.TTL > 0 && time.Since(rec.StoredAt) > c.TTL {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(rec.Data, v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *Cache) Store(key, version string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	rec, err := json.Marshal(record{Version: version, StoredAt: time.Now(), Data: data})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return err
+	}
+	path := c.path(key)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, rec, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
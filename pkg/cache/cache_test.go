@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name     string
+		storeKey string
+		storeVer string
+		// Возраст записи на момент чтения
+		age     time.Duration
+		ttl     time.Duration
+		loadKey string
+		loadVer string
+		wantHit bool
+	}{
+		{name: "hit", storeKey: "abc", storeVer: "1", loadKey: "abc", loadVer: "1", wantHit: true},
+		{name: "other version", storeKey: "abc", storeVer: "1", loadKey: "abc", loadVer: "2"},
+		{name: "missing", storeKey: "abc", storeVer: "1", loadKey: "def", loadVer: "1"},
+		{name: "expired", storeKey: "abc", storeVer: "1", age: 2 * time.Hour, ttl: time.Hour, loadKey: "abc", loadVer: "1"},
+		{name: "within ttl", storeKey: "abc", storeVer: "1", age: 30 * time.Minute, ttl: time.Hour, loadKey: "abc", loadVer: "1", wantHit: true},
+		{name: "branch key", storeKey: "abc/branch/def", storeVer: "1", loadKey: "abc/branch/def", loadVer: "1", wantHit: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(t.TempDir(), tt.ttl)
+			if err := c.Store(tt.storeKey, tt.storeVer, payload{Name: "Checkout"}); err != nil {
+				t.Fatal(err)
+			}
+			if tt.age > 0 {
+				storeAt(t, c, tt.storeKey, tt.storeVer, time.Now().Add(-tt.age))
+			}
+
+			var got payload
+			hit, err := c.Load(tt.loadKey, tt.loadVer, &got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hit != tt.wantHit {
+				t.Errorf("hit = %v, want %v", hit, tt.wantHit)
+			}
+			if hit && got.Name != "Checkout" {
+				t.Errorf("name = %q", got.Name)
+			}
+		})
+	}
+}
+
+// storeAt перезаписывает запись с заданным временем сохранения.
+func storeAt(t *testing.T, c *Cache, key, version string, at time.Time) {
+	t.Helper()
+	rec, err := json.Marshal(record{Version: version, StoredAt: at, Data: json.RawMessage(`{"name":"Checkout"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(c.path(key), rec, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPathIsSafe(t *testing.T) {
+	c := New("/cache", 0)
+	tests := map[string]string{
+		"abc":            "/cache/abc.json",
+		"abc/branch/def": "/cache/abc_branch_def.json",
+		"../escape":      "/cache/.._escape.json",
+	}
+	for key, want := range tests {
+		if got := c.path(key); got != want {
+			t.Errorf("path(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestLoadCorrupted(t *testing.T) {
+	c := New(t.TempDir(), 0)
+	if err := os.WriteFile(filepath.Join(c.Dir, "abc.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var v struct{}
+	if hit, err := c.Load("abc", "1", &v); hit || err == nil {
+		t.Errorf("Load = %v, %v; want miss with error", hit, err)
+	}
+}
@@ -17,4 +17,4 @@ func Load(path string) (*Config, error) {
 		return nil, err
 	}
 	return &cfg, nil
-}
\ No newline at end of file
+}
@@ -1,15 +1,72 @@
 package config
 
+import "time"
+
 type Config struct {
-	Figma    FigmaConfig  `yaml:"figma"`
-	Schedule string       `yaml:"schedule"`
-	Email    EmailConfig  `yaml:"email"`
-	Report   ReportConfig `yaml:"report"`
+	Figma    FigmaConfig   `yaml:"figma"`
+	Schedule string        `yaml:"schedule"`
+	Email    EmailConfig   `yaml:"email"`
+	Report   ReportConfig  `yaml:"report"`
+	Webhook  WebhookConfig `yaml:"webhook,omitempty"`
 }
 
 type FigmaConfig struct {
 	Token    string   `yaml:"token"`
 	FileKeys []string `yaml:"file_keys"`
+	// Файлы с собственными токенами, псевдонимами и тегами
+	Files     []FileConfig `yaml:"files,omitempty"`
+	BaseURL   string       `yaml:"base_url,omitempty"`
+	UserAgent string       `yaml:"user_agent,omitempty"`
+	// Указатели, чтобы явный 0 отключал повторы, а не означал «по умолчанию»
+	MaxRetries  *int `yaml:"max_retries,omitempty"`
+	RetryBudget *int `yaml:"retry_budget,omitempty"`
+	// Ограничения для запросов узлов
+	MaxURLLength    int `yaml:"max_url_length,omitempty"`
+	NodeConcurrency int `yaml:"node_concurrency,omitempty"`
+	// Поиск файлов в командах и проектах
+	TeamIDs    []string        `yaml:"team_ids,omitempty"`
+	ProjectIDs []string        `yaml:"project_ids,omitempty"`
+	Discovery  DiscoveryConfig `yaml:"discovery,omitempty"`
+	// OAuth-приложение вместо персонального токена
+	OAuth    OAuthConfig  `yaml:"oauth,omitempty"`
+	Cache    CacheConfig  `yaml:"cache,omitempty"`
+	Branches BranchConfig `yaml:"branches,omitempty"`
+}
+
+type BranchConfig struct {
+	Enabled         bool `yaml:"enabled"`
+	IncludeArchived bool `yaml:"include_archived,omitempty"`
+}
+
+// CacheConfig включает кэш ответов Figma, если задан Dir.
+type CacheConfig struct {
+	Dir string        `yaml:"dir,omitempty"`
+	TTL time.Duration `yaml:"ttl,omitempty"`
+}
+
+type OAuthConfig struct {
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url"`
+	Scopes       []string `yaml:"scopes,omitempty"`
+	TokenFile    string   `yaml:"token_file,omitempty"`
+	AuthURL      string   `yaml:"auth_url,omitempty"`
+	TokenURL     string   `yaml:"token_url,omitempty"`
+	RefreshURL   string   `yaml:"refresh_url,omitempty"`
+}
+
+type FileConfig struct {
+	Key   string   `yaml:"key"`
+	Alias string   `yaml:"alias,omitempty"`
+	Tags  []string `yaml:"tags,omitempty"`
+	// Персональный токен для этого файла вместо figma.token
+	Token string `yaml:"token,omitempty"`
+}
+
+type DiscoveryConfig struct {
+	Include            []string `yaml:"include,omitempty"`
+	Exclude            []string `yaml:"exclude,omitempty"`
+	ModifiedWithinDays int      `yaml:"modified_within_days,omitempty"`
 }
 
 type EmailConfig struct {
@@ -21,6 +78,16 @@ type EmailConfig struct {
 	To           []string `yaml:"to"`
 	Subject      string   `yaml:"subject"`
 	Body         string   `yaml:"body"`
+	// Добавлять отчёт в Markdown в текст письма
+	MarkdownBody bool `yaml:"markdown_body,omitempty"`
+	// Получатели, которым нужен отчёт в своём формате
+	Recipients []EmailRecipient `yaml:"recipients,omitempty"`
+}
+
+type EmailRecipient struct {
+	Address string `yaml:"address"`
+	// Формат вложения, пустой — report.format
+	Format string `yaml:"format,omitempty"`
 }
 
 type ReportField struct {
@@ -31,4 +98,57 @@ type ReportField struct {
 
 type ReportConfig struct {
 	Fields []ReportField `yaml:"fields"`
-}
\ No newline at end of file
+	// Формат вложения: xlsx (по умолчанию), csv, json или ndjson
+	Format string `yaml:"format,omitempty"`
+	// Комментарии на холсте и на удалённых узлах
+	IncludeUnanchored bool            `yaml:"include_unanchored,omitempty"`
+	Sort              []SortRule      `yaml:"sort,omitempty"`
+	Filters           []FilterRule    `yaml:"filters,omitempty"`
+	Thumbnails        ThumbnailConfig `yaml:"thumbnails,omitempty"`
+	Markdown          MarkdownConfig  `yaml:"markdown,omitempty"`
+	// Только комментарии после версии с этим названием или ID
+	SinceVersion string `yaml:"since_version,omitempty"`
+}
+
+type ThumbnailConfig struct {
+	Scale    float64       `yaml:"scale,omitempty"`
+	Format   string        `yaml:"format,omitempty"`
+	CacheDir string        `yaml:"cache_dir,omitempty"`
+	CacheTTL time.Duration `yaml:"cache_ttl,omitempty"`
+}
+
+type MarkdownConfig struct {
+	// Подразделы по страницам внутри раздела файла
+	GroupByPage bool `yaml:"group_by_page,omitempty"`
+}
+
+type SortRule struct {
+	Field string `yaml:"field"`
+	Desc  bool   `yaml:"desc,omitempty"`
+}
+
+// FilterRule оставляет в отчёте строки, у которых значение поля
+// удовлетворяет условию. Op: =, !=, >, >=, <, <=, contains, glob, has.
+type FilterRule struct {
+	Field string `yaml:"field"`
+	Op    string `yaml:"op,omitempty"`
+	Value string `yaml:"value"`
+}
+
+// WebhookConfig настраивает приём вебхуков Figma командой serve.
+type WebhookConfig struct {
+	// Публичный адрес, который регистрируется в Figma
+	Endpoint  string       `yaml:"endpoint,omitempty"`
+	Listen    string       `yaml:"listen"`
+	Path      string       `yaml:"path,omitempty"`
+	Passcode  string       `yaml:"passcode"`
+	StateFile string       `yaml:"state_file,omitempty"`
+	Notify    NotifyConfig `yaml:"notify"`
+}
+
+type NotifyConfig struct {
+	// Отправлять события на адреса из email.to
+	Email bool `yaml:"email,omitempty"`
+	// Входящие вебхуки Slack/Mattermost и совместимые
+	URLs []string `yaml:"urls,omitempty"`
+}
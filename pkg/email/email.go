@@ -27,18 +27,45 @@ func NewSender(cfg Config) *Sender {
 	return &Sender{cfg: cfg}
 }
 
-func (s *Sender) Send(data []byte, filename string) error {
+// Send отправляет письмо с вложением; пустой contentType оставляет
+// определение типа на почтовый клиент.
+func (s *Sender) Send(data []byte, filename, contentType string) error {
+	return s.SendTo(s.cfg.To, s.cfg.Body, data, filename, contentType)
+}
+
+// SendTo отправляет вложение указанным получателям с текстом письма
+// вместо Config.To и Config.Body.
+func (s *Sender) SendTo(to []string, body string, data []byte, filename, contentType string) error {
 	m := gomail.NewMessage()
 	m.SetHeader("From", s.cfg.From)
-	m.SetHeader("To", s.cfg.To...)
+	m.SetHeader("To", to...)
 	m.SetHeader("Subject", s.cfg.Subject)
-	m.SetBody("text/plain", s.cfg.Body)
+	m.SetBody("text/plain", body)
 
-	m.Attach(filename, gomail.SetCopyFunc(func(w io.Writer) error {
+	settings := []gomail.FileSetting{gomail.SetCopyFunc(func(w io.Writer) error {
 		_, err := io.Copy(w, bytes.NewReader(data))
 		return err
-	}))
+	})}
+	if contentType != "" {
+		settings = append(settings, gomail.SetHeader(map[string][]string{"Content-Type": {contentType}}))
+	}
+	m.Attach(filename, settings...)
 
+	return s.dialAndSend(m)
+}
+
+// SendText отправляет письмо без вложений с указанными темой и текстом.
+func (s *Sender) SendText(subject, body string) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", s.cfg.From)
+	m.SetHeader("To", s.cfg.To...)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/plain", body)
+
+	return s.dialAndSend(m)
+}
+
+func (s *Sender) dialAndSend(m *gomail.Message) error {
 	d := gomail.NewDialer(
 		s.cfg.SMTPHost,
 		s.cfg.SMTPPort,
@@ -50,4 +77,4 @@ func (s *Sender) Send(data []byte, filename string) error {
 		return fmt.Errorf("failed to send email: %w", err)
 	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,32 @@
+package figma
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+type Branch struct {
+	Key          string    `json:"key"`
+	Name         string    `json:"name"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	LastModified time.Time `json:"last_modified"`
+	Archived     bool      `json:"archived"`
+}
+
+// GetBranches возвращает имя основного файла и список его веток.
+func (c *Client) GetBranches(fileKey string) (string, []Branch, error) {
+	params := url.Values{}
+	params.Add("depth", "1")
+	params.Add("branch_data", "true")
+
+	var response struct {
+		Name     string   `json:"name"`
+		Branches []Branch `json:"branches"`
+	}
+	if err := c.get(fmt.Sprintf("/v1/files/%s", fileKey), params, &response); err != nil {
+		return "", nil, err
+	}
+
+	return response.Name, response.Branches, nil
+}
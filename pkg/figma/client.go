@@ -0,0 +1,179 @@
+package figma
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultBaseURL         = "https://api.figma.com"
+	DefaultMaxURLLength    = 4000
+	DefaultNodeConcurrency = 4
+)
+
+type TokenSource interface {
+	AccessToken() (string, error)
+}
+
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	UserAgent  string
+	Token      string
+	// Если задан, запросы авторизуются OAuth-токеном вместо Token
+	TokenSource TokenSource
+	Retry       RetryPolicy
+
+	MaxURLLength    int
+	NodeConcurrency int
+
+	// Общий для клиента и его копий из WithToken
+	budget *retryBudget
+}
+
+func NewClient(token string) *Client {
+	return &Client{
+		BaseURL:    DefaultBaseURL,
+		HTTPClient: http.DefaultClient,
+		Token:      token,
+		Retry:      DefaultRetryPolicy,
+
+		MaxURLLength:    DefaultMaxURLLength,
+		NodeConcurrency: DefaultNodeConcurrency,
+
+		budget: &retryBudget{},
+	}
+}
+
+// WithToken возвращает копию клиента с другим персональным токеном.
+// Бюджет повторов у копии общий с исходным клиентом.
+func (c *Client) WithToken(token string) *Client {
+	clone := *c
+	clone.Token = token
+	clone.TokenSource = nil
+	return &clone
+}
+
+func (c *Client) endpoint(path string, query url.Values) string {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	u := strings.TrimRight(base, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+func (c *Client) get(path string, query url.Values, v interface{}) error {
+	return c.send("GET", path, query, nil, v)
+}
+
+// send выполняет запрос с JSON-телом body (если оно не nil) и декодирует
+// ответ в v (если v не nil).
+func (c *Client) send(method, path string, query url.Values, body, v interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	resp, err := c.do(method, path, query, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (c *Client) authorize(req *http.Request) error {
+	if c.TokenSource == nil {
+		req.Header.Set("X-FIGMA-TOKEN", c.Token)
+		return nil
+	}
+
+	token, err := c.TokenSource.AccessToken()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (c *Client) do(method, path string, query url.Values, payload []byte) (*http.Response, error) {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	for attempt := 0; ; attempt++ {
+		var body io.Reader
+		if payload != nil {
+			body = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequest(method, c.endpoint(path, query), body)
+		if err != nil {
+			return nil, err
+		}
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if err := c.authorize(req); err != nil {
+			return nil, err
+		}
+		if c.UserAgent != "" {
+			req.Header.Set("User-Agent", c.UserAgent)
+		}
+
+		resp, err := httpClient.Do(req)
+		if err == nil && resp.StatusCode == 200 {
+			return resp, nil
+		}
+
+		var lastErr error
+		wait := c.Retry.backoff(attempt)
+		if err != nil {
+			lastErr = err
+			// Изменяющий запрос мог дойти до сервера, повтор создал бы дубликат
+			if method != "GET" {
+				return nil, lastErr
+			}
+		} else {
+			lastErr = newAPIError(method, path, resp)
+			resp.Body.Close()
+			if !isTransient(resp.StatusCode) || (method != "GET" && resp.StatusCode != http.StatusTooManyRequests) {
+				return nil, lastErr
+			}
+			if d, ok := retryAfter(resp); ok {
+				// Повтор раньше срока снова получил бы 429, поэтому дольше
+				// MaxBackoff не ждём и сразу возвращаем ошибку
+				if c.Retry.MaxBackoff > 0 && d > c.Retry.MaxBackoff {
+					return nil, fmt.Errorf("rate limited, server asked to wait %v: %w", d.Round(time.Second), lastErr)
+				}
+				wait = d
+			}
+		}
+
+		if attempt >= c.Retry.MaxRetries {
+			return nil, fmt.Errorf("giving up after %d retries: %w", attempt, lastErr)
+		}
+		if c.budget != nil && !c.budget.take(c.Retry.Budget) {
+			return nil, fmt.Errorf("%w (%d retries per run): %w", ErrRetryBudgetExhausted, c.Retry.Budget, lastErr)
+		}
+		time.Sleep(wait)
+	}
+}
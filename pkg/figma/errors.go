@@ -0,0 +1,109 @@
+package figma
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// APIError — ответ Figma API с кодом, отличным от 200.
+type APIError struct {
+	StatusCode int
+	Method     string
+	Endpoint   string
+	// Сообщение из тела ответа ("err" или "message")
+	Message string
+	Body    []byte
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("figma: %s %s: HTTP %d %s", e.Method, e.Endpoint, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func newAPIError(method, endpoint string, resp *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     method,
+		Endpoint:   endpoint,
+	}
+
+	apiErr.Body, _ = io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var body struct {
+		Err     string `json:"err"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(apiErr.Body, &body) == nil {
+		apiErr.Message = body.Err
+		if apiErr.Message == "" {
+			apiErr.Message = body.Message
+		}
+	}
+	return apiErr
+}
+
+func hasStatus(err error, codes ...int) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, code := range codes {
+		if apiErr.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests) || errors.Is(err, ErrRetryBudgetExhausted)
+}
+
+// StatusCode возвращает HTTP-код ошибки API или 0, если ошибка не от API.
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// Reason кратко описывает причину ошибки для отчёта.
+func Reason(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case IsUnauthorized(err):
+		return "unauthorized: token is invalid or expired"
+	case IsForbidden(err):
+		return "forbidden: no access to the resource"
+	case IsNotFound(err):
+		return "not found"
+	case IsRateLimited(err):
+		return "rate limited"
+	case errors.Is(err, ErrVersionNotFound):
+		return "version not found"
+	case StatusCode(err) >= 500:
+		return "Figma server error"
+	case StatusCode(err) != 0:
+		return "request rejected"
+	}
+	return "request failed"
+}
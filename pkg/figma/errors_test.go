@@ -0,0 +1,58 @@
+package figma
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	apiError := func(code int, body string) error {
+		resp := &http.Response{StatusCode: code, Body: io.NopCloser(strings.NewReader(body))}
+		return fmt.Errorf("getting comments: %w", newAPIError("GET", "/v1/files/KEY/comments", resp))
+	}
+
+	tests := []struct {
+		name      string
+		err       error
+		wantMsg   string
+		wantCode  int
+		reason    string
+		notFound  bool
+		forbidden bool
+	}{
+		{name: "err field", err: apiError(404, `{"status":404,"err":"Not found"}`), wantMsg: "Not found", wantCode: 404, reason: "not found", notFound: true},
+		{name: "message field", err: apiError(403, `{"status":403,"message":"Invalid scope"}`), wantMsg: "Invalid scope", wantCode: 403, reason: "forbidden: no access to the resource", forbidden: true},
+		{name: "not json", err: apiError(502, `<html>Bad gateway</html>`), wantCode: 502, reason: "Figma server error"},
+		{name: "unauthorized", err: apiError(401, `{"err":"Invalid token"}`), wantMsg: "Invalid token", wantCode: 401, reason: "unauthorized: token is invalid or expired"},
+		{name: "rate limited", err: apiError(429, ``), wantCode: 429, reason: "rate limited"},
+		{name: "bad request", err: apiError(400, `{"err":"Bad ids"}`), wantMsg: "Bad ids", wantCode: 400, reason: "request rejected"},
+		{
+			name:     "budget exhausted",
+			err:      fmt.Errorf("%w (10 retries per run): %w", ErrRetryBudgetExhausted, apiError(503, ``)),
+			wantCode: 503,
+			reason:   "rate limited",
+		},
+		{name: "version", err: fmt.Errorf("file KEY: %w", ErrVersionNotFound), reason: "version not found"},
+		{name: "network", err: errors.New("connection refused"), reason: "request failed"},
+	}
+
+	for _, tt := range tests {
+		var apiErr *APIError
+		if errors.As(tt.err, &apiErr) && apiErr.Message != tt.wantMsg {
+			t.Errorf("%s: message = %q, want %q", tt.name, apiErr.Message, tt.wantMsg)
+		}
+		if got := StatusCode(tt.err); got != tt.wantCode {
+			t.Errorf("%s: StatusCode = %d, want %d", tt.name, got, tt.wantCode)
+		}
+		if got := Reason(tt.err); got != tt.reason {
+			t.Errorf("%s: Reason = %q, want %q", tt.name, got, tt.reason)
+		}
+		if IsNotFound(tt.err) != tt.notFound || IsForbidden(tt.err) != tt.forbidden {
+			t.Errorf("%s: IsNotFound = %v, IsForbidden = %v", tt.name, IsNotFound(tt.err), IsForbidden(tt.err))
+		}
+	}
+}
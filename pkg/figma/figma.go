@@ -1,74 +1,199 @@
 package figma
 
 import (
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 )
 
-func GetComments(fileKey, token string) ([]Comment, error) {
-	url := fmt.Sprintf("https://api.figma.com/v1/files/%s/comments", fileKey)
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("X-FIGMA-TOKEN", token)
+func (c *Client) GetComments(fileKey string) ([]Comment, error) {
+	var response struct {
+		Comments []Comment `json:"comments"`
+	}
+	if err := c.get(fmt.Sprintf("/v1/files/%s/comments", fileKey), nil, &response); err != nil {
+		return nil, err
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	return response.Comments, nil
+}
+
+// GetFile загружает файл; depth <= 0 означает полное дерево документа.
+func (c *Client) GetFile(fileKey string, depth int) (*File, error) {
+	params := url.Values{}
+	if depth > 0 {
+		params.Add("depth", strconv.Itoa(depth))
+	}
+
+	var file File
+	if err := c.get(fmt.Sprintf("/v1/files/%s", fileKey), params, &file); err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+// GetFileTree возвращает документ только с узлами nodeIDs, их предками
+// и потомками. ID запрашиваются частями, части документа объединяются.
+func (c *Client) GetFileTree(fileKey string, nodeIDs []string) (*File, error) {
+	results, err := getChunked[File](c, fmt.Sprintf("/v1/files/%s", fileKey), url.Values{}, nodeIDs)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	if len(results) == 0 {
+		return &File{}, nil
+	}
+
+	merged := &results[0]
+	for i := range results[1:] {
+		mergeDocument(&merged.Document, &results[i+1].Document)
+	}
+	return merged, nil
+}
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+// mergeDocument добавляет в dst узлы src, которых в нём ещё нет.
+func mergeDocument(dst, src *Document) {
+	index := make(map[string]int, len(dst.Children))
+	for i, child := range dst.Children {
+		index[child.ID] = i
+	}
+	for i := range src.Children {
+		child := &src.Children[i]
+		if j, ok := index[child.ID]; ok {
+			mergeDocument(&dst.Children[j], child)
+			continue
+		}
+		index[child.ID] = len(dst.Children)
+		dst.Children = append(dst.Children, *child)
 	}
+}
 
+// GetFileMeta возвращает метаданные файла без загрузки документа.
+func (c *Client) GetFileMeta(fileKey string) (*FileMeta, error) {
 	var response struct {
-		Comments []Comment `json:"comments"`
+		File FileMeta `json:"file"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	if err := c.get(fmt.Sprintf("/v1/files/%s/meta", fileKey), nil, &response); err != nil {
 		return nil, err
 	}
 
-	return response.Comments, nil
+	return &response.File, nil
 }
 
-func GetFileNodes(fileKey, token string, nodeIDs []string) (*FileNodes, error) {
+func (c *Client) GetFileNodes(fileKey string, nodeIDs []string) (*FileNodes, error) {
 	params := url.Values{}
-	params.Add("ids", strings.Join(nodeIDs, ","))
+	params.Add("depth", "1")
 
-	url := fmt.Sprintf("https://api.figma.com/v1/files/%s/nodes?%s&depth=1",
-		fileKey, params.Encode())
-
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("X-FIGMA-TOKEN", token)
-
-	resp, err := http.DefaultClient.Do(req)
+	results, err := getChunked[FileNodes](c, fmt.Sprintf("/v1/files/%s/nodes", fileKey), params, nodeIDs)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	merged := &FileNodes{Nodes: make(map[string]*Node, len(nodeIDs))}
+	for _, result := range results {
+		if merged.Name == "" {
+			merged.Name = result.Name
+		}
+		for id, node := range result.Nodes {
+			merged.Nodes[id] = node
+		}
 	}
 
-	var nodesResponse FileNodes
-	if err := json.NewDecoder(resp.Body).Decode(&nodesResponse); err != nil {
-		return nil, err
+	return merged, nil
+}
+
+// getChunked запрашивает ids частями, укладывающимися в MaxURLLength,
+// не более NodeConcurrency запросов одновременно. Ответы возвращаются
+// в порядке частей.
+func getChunked[T any](c *Client, path string, params url.Values, ids []string) ([]T, error) {
+	chunks := c.chunkIDs(path, params, ids)
+
+	results := make([]T, len(chunks))
+	errs := make([]error, len(chunks))
+
+	concurrency := c.NodeConcurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk []string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			errs[i] = c.get(path, withIDs(params, chunk), &results[i])
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+func withIDs(params url.Values, ids []string) url.Values {
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
 	}
+	query.Set("ids", strings.Join(ids, ","))
+	return query
+}
+
+// chunkIDs делит ID узлов на группы так, чтобы URL запроса
+// не превышал MaxURLLength.
+func (c *Client) chunkIDs(path string, params url.Values, nodeIDs []string) [][]string {
+	ids := append([]string(nil), nodeIDs...)
+	sort.Strings(ids)
 
-	return &nodesResponse, nil
+	maxLength := c.MaxURLLength
+	if maxLength <= 0 {
+		maxLength = DefaultMaxURLLength
+	}
+	baseLength := len(c.endpoint(path, withIDs(params, nil)))
+	separatorLength := len(url.QueryEscape(","))
+
+	var chunks [][]string
+	var chunk []string
+	length := baseLength
+	for _, id := range ids {
+		idLength := len(url.QueryEscape(id))
+		if len(chunk) > 0 {
+			idLength += separatorLength
+		}
+		if len(chunk) > 0 && length+idLength > maxLength {
+			chunks = append(chunks, chunk)
+			chunk = nil
+			length = baseLength
+			idLength -= separatorLength
+		}
+		chunk = append(chunk, id)
+		length += idLength
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
 }
 
-func FilterParentComments(comments []Comment) ([]Comment, []string) {
-	var parentComments []Comment
+// FilterNodeThreads оставляет треды, привязанные к узлу, и возвращает
+// уникальные ID этих узлов.
+func FilterNodeThreads(threads []Thread) ([]Thread, []string) {
+	var nodeThreads []Thread
 	nodeIDMap := make(map[string]bool)
 
-	for _, comment := range comments {
-		if comment.ParentID == "" && comment.ClientMeta.NodeID != "" {
-			parentComments = append(parentComments, comment)
-			nodeIDMap[comment.ClientMeta.NodeID] = true
+	for _, thread := range threads {
+		if thread.Root.ClientMeta.NodeID != "" {
+			nodeThreads = append(nodeThreads, thread)
+			nodeIDMap[thread.Root.ClientMeta.NodeID] = true
 		}
 	}
 
@@ -77,5 +202,5 @@ func FilterParentComments(comments []Comment) ([]Comment, []string) {
 		nodeIDs = append(nodeIDs, id)
 	}
 
-	return parentComments, nodeIDs
-}
\ No newline at end of file
+	return nodeThreads, nodeIDs
+}
@@ -0,0 +1,162 @@
+package figma
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestChunkIDs(t *testing.T) {
+	ids := func(n int) []string {
+		out := make([]string, n)
+		for i := range out {
+			out[i] = strings.Repeat("1", 3) + ":" + strings.Repeat("2", 3) + string(rune('a'+i%26))
+		}
+		return out
+	}
+
+	tests := []struct {
+		name       string
+		ids        []string
+		maxLength  int
+		wantChunks int
+	}{
+		{name: "empty", ids: nil, maxLength: 200, wantChunks: 0},
+		{name: "fits in one", ids: ids(3), maxLength: 2000, wantChunks: 1},
+		{name: "split", ids: ids(40), maxLength: 200, wantChunks: 4},
+		// Один слишком длинный ID всё равно уходит отдельным запросом
+		{name: "id longer than limit", ids: []string{strings.Repeat("9", 300)}, maxLength: 100, wantChunks: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient("token")
+			c.BaseURL = "https://api.example.com"
+			c.MaxURLLength = tt.maxLength
+			params := url.Values{"depth": {"1"}}
+
+			chunks := c.chunkIDs("/v1/files/key/nodes", params, tt.ids)
+			if len(chunks) != tt.wantChunks {
+				t.Fatalf("got %d chunks, want %d", len(chunks), tt.wantChunks)
+			}
+
+			total := 0
+			for _, chunk := range chunks {
+				total += len(chunk)
+				u := c.endpoint("/v1/files/key/nodes", withIDs(params, chunk))
+				if len(chunk) > 1 && len(u) > tt.maxLength {
+					t.Errorf("URL length %d exceeds %d", len(u), tt.maxLength)
+				}
+			}
+			if total != len(tt.ids) {
+				t.Errorf("chunks hold %d ids, want %d", total, len(tt.ids))
+			}
+		})
+	}
+}
+
+func TestGetFileNodesChunked(t *testing.T) {
+	var mu sync.Mutex
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+
+		var nodes []string
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			nodes = append(nodes, `"`+id+`":{"document":{"id":"`+id+`","name":"Node `+id+`"}}`)
+		}
+		w.Write([]byte(`{"name":"File","nodes":{` + strings.Join(nodes, ",") + `}}`))
+	}))
+	defer server.Close()
+
+	c := testClient(server)
+	c.MaxURLLength = len(server.URL) + 60
+	c.NodeConcurrency = 2
+
+	var ids []string
+	for i := 0; i < 30; i++ {
+		ids = append(ids, "1:"+strings.Repeat("0", i%5)+string(rune('0'+i%10)))
+	}
+	ids = append(ids, ids...)
+
+	nodes, err := c.GetFileNodes("key", ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nodes.Name != "File" {
+		t.Errorf("name = %q", nodes.Name)
+	}
+	for _, id := range ids {
+		if nodes.Nodes[id] == nil || nodes.Nodes[id].Document.Name != "Node "+id {
+			t.Errorf("node %s missing", id)
+		}
+	}
+	if requests < 2 {
+		t.Errorf("got %d requests, want the ids split into several", requests)
+	}
+}
+
+func TestGetFileTree(t *testing.T) {
+	// Предки каждого узла: страница и фрейм
+	parents := map[string][2]string{
+		"1:3": {"0:1", "1:1"},
+		"1:4": {"0:1", "1:1"},
+		"2:5": {"0:2", "2:1"},
+	}
+
+	var mu sync.Mutex
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids := r.URL.Query().Get("ids")
+		mu.Lock()
+		requested = append(requested, ids)
+		mu.Unlock()
+
+		// Как и Figma, возвращаем только ветки до запрошенных узлов
+		root := Document{ID: "0:0", Type: "DOCUMENT"}
+		for _, id := range strings.Split(ids, ",") {
+			p := parents[id]
+			branch := Document{ID: p[0], Name: "Page " + p[0], Type: "CANVAS", Children: []Document{
+				{ID: p[1], Name: "Frame " + p[1], Type: "FRAME", Children: []Document{{ID: id, Name: "Node " + id}}},
+			}}
+			mergeDocument(&root, &Document{Children: []Document{branch}})
+		}
+		json.NewEncoder(w).Encode(File{Name: "File", Document: root})
+	}))
+	defer server.Close()
+
+	c := testClient(server)
+	c.MaxURLLength = len(server.URL) + len("/v1/files/key?ids=") + 5
+
+	file, err := c.GetFileTree("key", []string{"2:5", "1:3", "1:4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requested) != 3 {
+		t.Errorf("requests = %q, want one per node", requested)
+	}
+	if file.Name != "File" || len(file.Document.Children) != 2 {
+		t.Fatalf("file = %+v, want two pages", file)
+	}
+
+	tree := NewTree(&file.Document)
+	for id, p := range parents {
+		var names []string
+		for _, node := range tree.Path(id) {
+			names = append(names, node.Name)
+		}
+		want := "Page " + p[0] + "/Frame " + p[1] + "/Node " + id
+		if got := strings.Join(names, "/"); got != want {
+			t.Errorf("path of %s = %q, want %q", id, got, want)
+		}
+	}
+	if frame := file.Document.Children[0].Children; len(frame) != 1 || len(frame[0].Children) != 2 {
+		t.Errorf("page 0:1 = %+v, want one frame with both nodes", file.Document.Children[0])
+	}
+}
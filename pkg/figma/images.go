@@ -0,0 +1,69 @@
+package figma
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type ImageOptions struct {
+	Scale  float64
+	Format string
+}
+
+// GetImages рендерит узлы и возвращает ссылки на изображения по ID узла.
+// Для узлов, которые не удалось отрендерить, ссылка пустая.
+func (c *Client) GetImages(fileKey string, nodeIDs []string, opts ImageOptions) (map[string]string, error) {
+	params := url.Values{}
+	if opts.Scale > 0 {
+		params.Add("scale", strconv.FormatFloat(opts.Scale, 'f', -1, 64))
+	}
+	if opts.Format != "" {
+		params.Add("format", opts.Format)
+	}
+
+	type imagesResponse struct {
+		Err    *string            `json:"err"`
+		Images map[string]*string `json:"images"`
+	}
+	results, err := getChunked[imagesResponse](c, fmt.Sprintf("/v1/images/%s", fileKey), params, nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	images := make(map[string]string, len(nodeIDs))
+	for _, result := range results {
+		if result.Err != nil && *result.Err != "" {
+			return nil, fmt.Errorf("figma: rendering images: %s", *result.Err)
+		}
+		for id, imageURL := range result.Images {
+			if imageURL != nil {
+				images[id] = *imageURL
+			}
+		}
+	}
+
+	return images, nil
+}
+
+// Download загружает готовое изображение по ссылке из GetImages.
+// Ссылки ведут на хранилище Figma, токен к ним не передаётся.
+func (c *Client) Download(imageURL string) ([]byte, error) {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Get(imageURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("downloading image: HTTP %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,41 @@
+package figma
+
+import (
+	"fmt"
+	"time"
+)
+
+type Project struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type ProjectFile struct {
+	Key          string    `json:"key"`
+	Name         string    `json:"name"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+func (c *Client) GetTeamProjects(teamID string) ([]Project, error) {
+	var response struct {
+		Projects []Project `json:"projects"`
+	}
+	if err := c.get(fmt.Sprintf("/v1/teams/%s/projects", teamID), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Projects, nil
+}
+
+func (c *Client) GetProjectFiles(projectID string) (string, []ProjectFile, error) {
+	var response struct {
+		Name  string        `json:"name"`
+		Files []ProjectFile `json:"files"`
+	}
+	if err := c.get(fmt.Sprintf("/v1/projects/%s/files", projectID), nil, &response); err != nil {
+		return "", nil, err
+	}
+
+	return response.Name, response.Files, nil
+}
@@ -0,0 +1,50 @@
+package figma
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+type Reaction struct {
+	User      User      `json:"user"`
+	Emoji     string    `json:"emoji"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (c *Client) GetReactions(fileKey, commentID string) ([]Reaction, error) {
+	var reactions []Reaction
+	params := url.Values{}
+	for {
+		var response struct {
+			Reactions  []Reaction `json:"reactions"`
+			Pagination struct {
+				NextPage string `json:"next_page"`
+			} `json:"pagination"`
+		}
+		path := fmt.Sprintf("/v1/files/%s/comments/%s/reactions", fileKey, commentID)
+		if err := c.get(path, params, &response); err != nil {
+			return nil, err
+		}
+		reactions = append(reactions, response.Reactions...)
+
+		cursor := nextCursor(response.Pagination.NextPage)
+		if cursor == "" || cursor == params.Get("cursor") {
+			break
+		}
+		params.Set("cursor", cursor)
+	}
+
+	return reactions, nil
+}
+
+func nextCursor(nextPage string) string {
+	if nextPage == "" {
+		return ""
+	}
+	u, err := url.Parse(nextPage)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get("cursor")
+}
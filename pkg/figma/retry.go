@@ -0,0 +1,99 @@
+package figma
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var ErrRetryBudgetExhausted = errors.New("figma: retry budget exhausted")
+
+type RetryPolicy struct {
+	// Попыток повтора на один запрос
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Общее число повторов на один запуск отчёта
+	Budget int
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 500 * time.Millisecond,
+	MaxBackoff: 30 * time.Second,
+	Budget:     20,
+}
+
+type retryBudget struct {
+	mu   sync.Mutex
+	used int
+}
+
+func (b *retryBudget) take(limit int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.used >= limit {
+		return false
+	}
+	b.used++
+	return true
+}
+
+func (b *retryBudget) reset() {
+	b.mu.Lock()
+	b.used = 0
+	b.mu.Unlock()
+}
+
+// ResetRetryBudget начинает новый запуск с полным бюджетом повторов.
+func (c *Client) ResetRetryBudget() {
+	if c.budget != nil {
+		c.budget.reset()
+	}
+}
+
+func isTransient(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff << uint(attempt)
+	if d <= 0 || d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	// Джиттер: случайная задержка в диапазоне [d/2, d)
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
+
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
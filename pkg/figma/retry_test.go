@@ -0,0 +1,139 @@
+package figma
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testClient возвращает клиент к server с короткими задержками повторов.
+func testClient(server *httptest.Server) *Client {
+	c := NewClient("token")
+	c.BaseURL = server.URL
+	c.Retry = RetryPolicy{
+		MaxRetries: 3,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 5 * time.Millisecond,
+		Budget:     20,
+	}
+	return c
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		statuses   []int
+		retryAfter string
+		maxRetries int
+		budget     int
+		wantCalls  int
+		wantStatus int
+		wantBudget bool
+		// Подстрока текста ошибки
+		wantErr string
+	}{
+		{name: "ok", method: "GET", statuses: []int{200}, maxRetries: 3, budget: 20, wantCalls: 1},
+		{name: "transient then ok", method: "GET", statuses: []int{503, 502, 200}, maxRetries: 3, budget: 20, wantCalls: 3},
+		{name: "gives up after max retries", method: "GET", statuses: []int{500, 500, 500}, maxRetries: 2, budget: 20, wantCalls: 3, wantStatus: 500},
+		{name: "zero retries", method: "GET", statuses: []int{429, 200}, maxRetries: 0, budget: 20, wantCalls: 1, wantStatus: 429},
+		{name: "not transient", method: "GET", statuses: []int{404, 200}, maxRetries: 3, budget: 20, wantCalls: 1, wantStatus: 404},
+		{name: "budget exhausted", method: "GET", statuses: []int{429, 429, 200}, maxRetries: 3, budget: 1, wantCalls: 2, wantStatus: 429, wantBudget: true},
+		{name: "post retried on 429", method: "POST", statuses: []int{429, 200}, maxRetries: 3, budget: 20, wantCalls: 2},
+		{name: "post not retried on 5xx", method: "POST", statuses: []int{503, 200}, maxRetries: 3, budget: 20, wantCalls: 1, wantStatus: 503},
+		{name: "short retry-after honoured", method: "GET", statuses: []int{429, 200}, retryAfter: "0", maxRetries: 3, budget: 20, wantCalls: 2},
+		// Ждать дольше MaxBackoff бессмысленно: ошибка возвращается сразу
+		{name: "long retry-after fails", method: "GET", statuses: []int{429, 200}, retryAfter: "240", maxRetries: 3, budget: 20, wantCalls: 1, wantStatus: 429, wantErr: "server asked to wait 4m0s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(calls.Add(1)) - 1
+				status := tt.statuses[min(n, len(tt.statuses)-1)]
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(status)
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			c := testClient(server)
+			c.Retry.MaxRetries = tt.maxRetries
+			c.Retry.Budget = tt.budget
+
+			start := time.Now()
+			err := c.send(tt.method, "/v1/test", nil, nil, nil)
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("request took %v", elapsed)
+			}
+
+			if got := int(calls.Load()); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+			if got := StatusCode(err); got != tt.wantStatus {
+				t.Errorf("status = %d, want %d (err: %v)", got, tt.wantStatus, err)
+			}
+			if tt.wantStatus == 0 && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.wantErr)
+			}
+			if got := errors.Is(err, ErrRetryBudgetExhausted); got != tt.wantBudget {
+				t.Errorf("budget exhausted = %v, want %v (err: %v)", got, tt.wantBudget, err)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{0, 50 * time.Millisecond, 100 * time.Millisecond},
+		{1, 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond, 800 * time.Millisecond},
+		{4, 500 * time.Millisecond, time.Second},
+		{62, 500 * time.Millisecond, time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			if d := p.backoff(tt.attempt); d < tt.min || d >= tt.max {
+				t.Errorf("backoff(%d) = %v, want in [%v, %v)", tt.attempt, d, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"2", 2 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if tt.value != "" {
+			resp.Header.Set("Retry-After", tt.value)
+		}
+		got, ok := retryAfter(resp)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
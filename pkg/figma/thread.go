@@ -0,0 +1,70 @@
+package figma
+
+import (
+	"sort"
+	"time"
+)
+
+type Thread struct {
+	Root    Comment
+	Replies []Comment
+}
+
+func (t Thread) ReplyCount() int {
+	return len(t.Replies)
+}
+
+func (t Thread) LastReply() *Comment {
+	if len(t.Replies) == 0 {
+		return nil
+	}
+	return &t.Replies[len(t.Replies)-1]
+}
+
+func (t Thread) LastActivity() time.Time {
+	last := t.Root.CreatedAt
+	if reply := t.LastReply(); reply != nil && reply.CreatedAt.After(last) {
+		last = reply.CreatedAt
+	}
+	if t.Root.ResolvedAt != nil && t.Root.ResolvedAt.After(last) {
+		last = *t.Root.ResolvedAt
+	}
+	return last
+}
+
+// Comments возвращает корневой комментарий и ответы в хронологическом порядке.
+func (t Thread) Comments() []Comment {
+	return append([]Comment{t.Root}, t.Replies...)
+}
+
+// BuildThreads собирает плоский список комментариев в треды.
+// Ответы без найденного корневого комментария отбрасываются.
+func BuildThreads(comments []Comment) []Thread {
+	var threads []Thread
+	index := make(map[string]int)
+
+	for _, comment := range comments {
+		if comment.ParentID == "" {
+			index[comment.ID] = len(threads)
+			threads = append(threads, Thread{Root: comment})
+		}
+	}
+
+	for _, comment := range comments {
+		if comment.ParentID == "" {
+			continue
+		}
+		if i, ok := index[comment.ParentID]; ok {
+			threads[i].Replies = append(threads[i].Replies, comment)
+		}
+	}
+
+	for i := range threads {
+		replies := threads[i].Replies
+		sort.SliceStable(replies, func(a, b int) bool {
+			return replies[a].CreatedAt.Before(replies[b].CreatedAt)
+		})
+	}
+
+	return threads
+}
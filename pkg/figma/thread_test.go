@@ -0,0 +1,100 @@
+package figma
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuildThreads(t *testing.T) {
+	at := func(minute int) time.Time {
+		return time.Date(2024, 5, 1, 10, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		comments []Comment
+		// ID корня -> ID ответов по порядку
+		want map[string][]string
+	}{
+		{
+			name: "no replies",
+			comments: []Comment{
+				{ID: "1", CreatedAt: at(0)},
+				{ID: "2", CreatedAt: at(1)},
+			},
+			want: map[string][]string{"1": nil, "2": nil},
+		},
+		{
+			name: "replies sorted by time",
+			comments: []Comment{
+				{ID: "3", ParentID: "1", CreatedAt: at(5)},
+				{ID: "1", CreatedAt: at(0)},
+				{ID: "2", ParentID: "1", CreatedAt: at(2)},
+			},
+			want: map[string][]string{"1": {"2", "3"}},
+		},
+		{
+			name: "orphan reply dropped",
+			comments: []Comment{
+				{ID: "1", CreatedAt: at(0)},
+				{ID: "2", ParentID: "missing", CreatedAt: at(1)},
+			},
+			want: map[string][]string{"1": nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			threads := BuildThreads(tt.comments)
+			got := make(map[string][]string)
+			for _, thread := range threads {
+				var replies []string
+				for _, reply := range thread.Replies {
+					replies = append(replies, reply.ID)
+				}
+				got[thread.Root.ID] = replies
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("threads = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThreadLastActivity(t *testing.T) {
+	at := func(minute int) time.Time {
+		return time.Date(2024, 5, 1, 10, minute, 0, 0, time.UTC)
+	}
+	resolved := at(30)
+
+	tests := []struct {
+		name   string
+		thread Thread
+		want   time.Time
+	}{
+		{"root only", Thread{Root: Comment{CreatedAt: at(0)}}, at(0)},
+		{"last reply", Thread{Root: Comment{CreatedAt: at(0)}, Replies: []Comment{{CreatedAt: at(5)}, {CreatedAt: at(10)}}}, at(10)},
+		{"resolved later", Thread{Root: Comment{CreatedAt: at(0), ResolvedAt: &resolved}, Replies: []Comment{{CreatedAt: at(10)}}}, at(30)},
+	}
+	for _, tt := range tests {
+		if got := tt.thread.LastActivity(); !got.Equal(tt.want) {
+			t.Errorf("%s: LastActivity() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFilterNodeThreads(t *testing.T) {
+	threads := []Thread{
+		{Root: Comment{ID: "1", ClientMeta: ClientMeta{NodeID: "1:2"}}},
+		{Root: Comment{ID: "2"}},
+		{Root: Comment{ID: "3", ClientMeta: ClientMeta{NodeID: "1:2"}}},
+	}
+	nodeThreads, ids := FilterNodeThreads(threads)
+	if len(nodeThreads) != 2 {
+		t.Errorf("got %d node threads, want 2", len(nodeThreads))
+	}
+	if !reflect.DeepEqual(ids, []string{"1:2"}) {
+		t.Errorf("ids = %v, want [1:2]", ids)
+	}
+}
@@ -0,0 +1,42 @@
+package figma
+
+type Tree struct {
+	nodes   map[string]*Document
+	parents map[string]string
+}
+
+func NewTree(root *Document) *Tree {
+	t := &Tree{
+		nodes:   make(map[string]*Document),
+		parents: make(map[string]string),
+	}
+	t.index(root, "")
+	return t
+}
+
+func (t *Tree) index(node *Document, parentID string) {
+	t.nodes[node.ID] = node
+	if parentID != "" {
+		t.parents[node.ID] = parentID
+	}
+	for i := range node.Children {
+		t.index(&node.Children[i], node.ID)
+	}
+}
+
+// Path возвращает цепочку узлов от страницы до самого узла.
+// Корневой узел DOCUMENT в цепочку не входит.
+func (t *Tree) Path(nodeID string) []*Document {
+	var path []*Document
+	for id := nodeID; id != ""; id = t.parents[id] {
+		node, ok := t.nodes[id]
+		if !ok {
+			return nil
+		}
+		if node.Type == "DOCUMENT" {
+			break
+		}
+		path = append([]*Document{node}, path...)
+	}
+	return path
+}
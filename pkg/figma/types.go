@@ -6,26 +6,91 @@ import (
 
 type Comment struct {
 	ID         string     `json:"id"`
+	FileKey    string     `json:"file_key"`
 	CreatedAt  time.Time  `json:"created_at"`
 	ResolvedAt *time.Time `json:"resolved_at"`
-	User       struct {
-		Handle string `json:"handle"`
-	} `json:"user"`
-	Message    string `json:"message"`
-	ClientMeta struct {
-		NodeID string `json:"node_id"`
-	} `json:"client_meta"`
-	ParentID string `json:"parent_id"`
+	User       User       `json:"user"`
+	Message    string     `json:"message"`
+	ClientMeta ClientMeta `json:"client_meta"`
+	ParentID   string     `json:"parent_id"`
+	// Номер комментария на холсте, есть только у корневых комментариев
+	OrderID string `json:"order_id"`
+	// nil, если реакции не пришли в ответе и их нужно запросить отдельно
+	Reactions []Reaction `json:"reactions"`
+}
+
+type User struct {
+	ID     string `json:"id"`
+	Handle string `json:"handle"`
+	ImgURL string `json:"img_url"`
+}
+
+type Vector struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// ClientMeta описывает положение комментария: точку или область на холсте
+// либо смещение относительно узла.
+type ClientMeta struct {
+	NodeID           string   `json:"node_id"`
+	NodeOffset       *Vector  `json:"node_offset"`
+	X                *float64 `json:"x"`
+	Y                *float64 `json:"y"`
+	RegionWidth      float64  `json:"region_width"`
+	RegionHeight     float64  `json:"region_height"`
+	CommentPinCorner string   `json:"comment_pin_corner"`
+}
+
+// Position возвращает смещение от узла, а для комментариев на холсте —
+// абсолютные координаты.
+func (m ClientMeta) Position() (Vector, bool) {
+	if m.NodeOffset != nil {
+		return *m.NodeOffset, true
+	}
+	if m.X != nil && m.Y != nil {
+		return Vector{X: *m.X, Y: *m.Y}, true
+	}
+	return Vector{}, false
+}
+
+func (m ClientMeta) HasRegion() bool {
+	return m.RegionWidth > 0 || m.RegionHeight > 0
 }
 
 type FileNodes struct {
-	Name  string          `json:"name"`
-	Nodes map[string]Node `json:"nodes"`
+	Name string `json:"name"`
+	// Для удалённых узлов API возвращает null, в карте им соответствует nil
+	Nodes map[string]*Node `json:"nodes"`
 }
 
 type Node struct {
-	Document struct {
-		Name string `json:"name"`
-		ID   string `json:"id"`
-	} `json:"document"`
-}
\ No newline at end of file
+	Document Document `json:"document"`
+}
+
+type Document struct {
+	ID       string     `json:"id"`
+	Name     string     `json:"name"`
+	Type     string     `json:"type"`
+	Children []Document `json:"children,omitempty"`
+}
+
+type File struct {
+	Name         string    `json:"name"`
+	LastModified time.Time `json:"lastModified"`
+	Version      string    `json:"version"`
+	Document     Document  `json:"document"`
+}
+
+type FileMeta struct {
+	Name          string    `json:"name"`
+	FolderName    string    `json:"folder_name"`
+	LastTouchedAt time.Time `json:"last_touched_at"`
+	Version       string    `json:"version"`
+	// figma или figjam
+	EditorType   string `json:"editorType"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	// Роль владельца токена в файле: owner, editor или viewer
+	Role string `json:"role"`
+	URL  string `json:"url"`
+}
@@ -0,0 +1,49 @@
+package figma
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ErrVersionNotFound — в истории файла нет версии с таким названием или ID.
+var ErrVersionNotFound = errors.New("version not found")
+
+type Version struct {
+	ID          string    `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Label       string    `json:"label"`
+	Description string    `json:"description"`
+	User        User      `json:"user"`
+}
+
+// GetVersions возвращает всю историю версий файла, от новых к старым.
+func (c *Client) GetVersions(fileKey string) ([]Version, error) {
+	var versions []Version
+	params := url.Values{}
+	for {
+		var response struct {
+			Versions   []Version `json:"versions"`
+			Pagination struct {
+				NextPage string `json:"next_page"`
+			} `json:"pagination"`
+		}
+		if err := c.get(fmt.Sprintf("/v1/files/%s/versions", fileKey), params, &response); err != nil {
+			return nil, err
+		}
+		versions = append(versions, response.Versions...)
+
+		next, err := url.Parse(response.Pagination.NextPage)
+		if response.Pagination.NextPage == "" || err != nil || len(response.Versions) == 0 {
+			break
+		}
+		nextParams := next.Query()
+		if nextParams.Encode() == params.Encode() {
+			break
+		}
+		params = nextParams
+	}
+
+	return versions, nil
+}
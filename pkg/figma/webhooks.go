@@ -0,0 +1,45 @@
+package figma
+
+import "fmt"
+
+type Webhook struct {
+	ID          string `json:"id"`
+	EventType   string `json:"event_type"`
+	TeamID      string `json:"team_id"`
+	Status      string `json:"status"`
+	ClientID    string `json:"client_id"`
+	Passcode    string `json:"passcode"`
+	Endpoint    string `json:"endpoint"`
+	Description string `json:"description"`
+}
+
+type CreateWebhookRequest struct {
+	EventType   string `json:"event_type"`
+	TeamID      string `json:"team_id"`
+	Endpoint    string `json:"endpoint"`
+	Passcode    string `json:"passcode"`
+	Description string `json:"description,omitempty"`
+}
+
+func (c *Client) ListTeamWebhooks(teamID string) ([]Webhook, error) {
+	var response struct {
+		Webhooks []Webhook `json:"webhooks"`
+	}
+	if err := c.get(fmt.Sprintf("/v2/teams/%s/webhooks", teamID), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Webhooks, nil
+}
+
+func (c *Client) CreateWebhook(req CreateWebhookRequest) (*Webhook, error) {
+	var webhook Webhook
+	if err := c.send("POST", "/v2/webhooks", nil, req, &webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (c *Client) DeleteWebhook(webhookID string) error {
+	return c.send("DELETE", fmt.Sprintf("/v2/webhooks/%s", webhookID), nil, nil, nil)
+}
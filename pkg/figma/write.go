@@ -0,0 +1,21 @@
+package figma
+
+import "fmt"
+
+// PostReply добавляет ответ в тред комментария commentID.
+func (c *Client) PostReply(fileKey, commentID, message string) (*Comment, error) {
+	body := struct {
+		Message   string `json:"message"`
+		CommentID string `json:"comment_id"`
+	}{message, commentID}
+
+	var comment Comment
+	if err := c.send("POST", fmt.Sprintf("/v1/files/%s/comments", fileKey), nil, body, &comment); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (c *Client) DeleteComment(fileKey, commentID string) error {
+	return c.send("DELETE", fmt.Sprintf("/v1/files/%s/comments/%s", fileKey, commentID), nil, nil, nil)
+}
@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/email"
+)
+
+type Notification struct {
+	Title string
+	Text  string
+	Link  string
+}
+
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+type Email struct {
+	Sender *email.Sender
+}
+
+func (e Email) Notify(n Notification) error {
+	body := n.Text
+	if n.Link != "" {
+		body += "\n\n" + n.Link
+	}
+	return e.Sender.SendText(n.Title, body)
+}
+
+// HTTP отправляет уведомление POST-запросом с JSON {"text": ...},
+// который понимают входящие вебхуки Slack и Mattermost.
+type HTTP struct {
+	URL    string
+	Client *http.Client
+}
+
+func (h HTTP) Notify(n Notification) error {
+	text := n.Title + "\n" + n.Text
+	if n.Link != "" {
+		text += "\n" + n.Link
+	}
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Post(h.URL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notify %s: HTTP %d", h.URL, resp.StatusCode)
+	}
+	return nil
+}
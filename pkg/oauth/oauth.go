@@ -0,0 +1,228 @@
+package oauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultAuthURL    = "https://www.figma.com/oauth"
+	DefaultTokenURL   = "https://api.figma.com/v1/oauth/token"
+	DefaultRefreshURL = "https://api.figma.com/v1/oauth/refresh"
+
+	// Токен обновляется заранее, чтобы не истечь посреди отчёта
+	refreshMargin = 5 * time.Minute
+)
+
+var ErrNoToken = errors.New("oauth: no token stored, run the auth command first")
+
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	TokenFile    string
+
+	AuthURL    string
+	TokenURL   string
+	RefreshURL string
+	HTTPClient *http.Client
+}
+
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	UserID       string    `json:"user_id,omitempty"`
+}
+
+func (t *Token) expiring(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && now.Add(refreshMargin).After(t.ExpiresAt)
+}
+
+func (c Config) AuthCodeURL(state string) string {
+	authURL := c.AuthURL
+	if authURL == "" {
+		authURL = DefaultAuthURL
+	}
+
+	params := url.Values{}
+	params.Set("client_id", c.ClientID)
+	params.Set("redirect_uri", c.RedirectURL)
+	params.Set("scope", strings.Join(c.Scopes, ","))
+	params.Set("state", state)
+	params.Set("response_type", "code")
+	return authURL + "?" + params.Encode()
+}
+
+// Exchange обменивает код авторизации на пару access/refresh токенов.
+func (c Config) Exchange(code string) (*Token, error) {
+	tokenURL := c.TokenURL
+	if tokenURL == "" {
+		tokenURL = DefaultTokenURL
+	}
+
+	form := url.Values{}
+	form.Set("client_id", c.ClientID)
+	form.Set("client_secret", c.ClientSecret)
+	form.Set("redirect_uri", c.RedirectURL)
+	form.Set("code", code)
+	form.Set("grant_type", "authorization_code")
+	return c.requestToken(tokenURL, form)
+}
+
+func (c Config) Refresh(refreshToken string) (*Token, error) {
+	refreshURL := c.RefreshURL
+	if refreshURL == "" {
+		refreshURL = DefaultRefreshURL
+	}
+
+	form := url.Values{}
+	form.Set("client_id", c.ClientID)
+	form.Set("client_secret", c.ClientSecret)
+	form.Set("refresh_token", refreshToken)
+	form.Set("grant_type", "refresh_token")
+
+	token, err := c.requestToken(refreshURL, form)
+	if err != nil {
+		return nil, err
+	}
+	// Эндпоинт обновления может не возвращать новый refresh-токен
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+	return token, nil
+}
+
+func (c Config) requestToken(endpoint string, form url.Values) (*Token, error) {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.PostForm(endpoint, form)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("oauth: %s: HTTP %d: %s", endpoint, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var response struct {
+		AccessToken  string          `json:"access_token"`
+		RefreshToken string          `json:"refresh_token"`
+		ExpiresIn    int64           `json:"expires_in"`
+		UserID       json.RawMessage `json:"user_id"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("oauth: decoding token response: %w", err)
+	}
+	if response.AccessToken == "" {
+		return nil, fmt.Errorf("oauth: %s: response has no access_token", endpoint)
+	}
+
+	token := &Token{
+		AccessToken:  response.AccessToken,
+		RefreshToken: response.RefreshToken,
+	}
+	// user_id приходит то числом, то строкой
+	if id := strings.Trim(string(response.UserID), `"`); id != "null" {
+		token.UserID = id
+	}
+	if response.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(response.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+func LoadToken(path string) (*Token, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var token Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("oauth: reading %s: %w", path, err)
+	}
+	return &token, nil
+}
+
+// SaveToken атомарно записывает токен в файл, доступный только владельцу.
+func SaveToken(path string, token *Token) error {
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return err
+		}
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// TokenSource выдаёт действующий access-токен, обновляя его перед
+// истечением и сохраняя результат в TokenFile.
+type TokenSource struct {
+	cfg   Config
+	mu    sync.Mutex
+	token *Token
+}
+
+func NewTokenSource(cfg Config) *TokenSource {
+	return &TokenSource{cfg: cfg}
+}
+
+func (s *TokenSource) AccessToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil {
+		token, err := LoadToken(s.cfg.TokenFile)
+		if err != nil {
+			return "", err
+		}
+		s.token = token
+	}
+
+	if s.token.expiring(time.Now()) {
+		if s.token.RefreshToken == "" {
+			return "", fmt.Errorf("oauth: access token expired and no refresh token is stored")
+		}
+		token, err := s.cfg.Refresh(s.token.RefreshToken)
+		if err != nil {
+			return "", fmt.Errorf("oauth: refreshing token: %w", err)
+		}
+		token.UserID = s.token.UserID
+		if err := SaveToken(s.cfg.TokenFile, token); err != nil {
+			return "", fmt.Errorf("oauth: saving refreshed token: %w", err)
+		}
+		s.token = token
+	}
+
+	return s.token.AccessToken, nil
+}
@@ -0,0 +1,163 @@
+package oauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExchange(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantToken  string
+		wantUserID string
+		wantErr    bool
+	}{
+		{name: "numeric user id", status: 200, body: `{"access_token":"a1","refresh_token":"r1","expires_in":3600,"user_id":42}`, wantToken: "a1", wantUserID: "42"},
+		{name: "string user id", status: 200, body: `{"access_token":"a1","refresh_token":"r1","expires_in":3600,"user_id":"42"}`, wantToken: "a1", wantUserID: "42"},
+		{name: "no access token", status: 200, body: `{"refresh_token":"r1"}`, wantErr: true},
+		{name: "rejected", status: 400, body: `{"error":"invalid_grant"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseForm(); err != nil {
+					t.Error(err)
+				}
+				for key, want := range map[string]string{
+					"client_id":     "id",
+					"client_secret": "secret",
+					"code":          "the-code",
+					"grant_type":    "authorization_code",
+					"redirect_uri":  "http://localhost:8765/callback",
+				} {
+					if got := r.PostForm.Get(key); got != want {
+						t.Errorf("%s = %q, want %q", key, got, want)
+					}
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			cfg := Config{
+				ClientID:     "id",
+				ClientSecret: "secret",
+				RedirectURL:  "http://localhost:8765/callback",
+				TokenURL:     server.URL,
+			}
+			token, err := cfg.Exchange("the-code")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if token.AccessToken != tt.wantToken || token.UserID != tt.wantUserID {
+				t.Errorf("token = %+v", token)
+			}
+			if until := time.Until(token.ExpiresAt); until < 59*time.Minute || until > time.Hour {
+				t.Errorf("expires in %v, want about an hour", until)
+			}
+		})
+	}
+}
+
+func TestTokenSourceRefresh(t *testing.T) {
+	tests := []struct {
+		name string
+		// Сохранённый токен
+		expiresIn    time.Duration
+		refreshToken string
+		// Ответ эндпоинта обновления
+		refreshBody string
+
+		wantRefreshes int32
+		wantAccess    string
+		wantRefresh   string
+		wantErr       bool
+	}{
+		{name: "valid token", expiresIn: time.Hour, refreshToken: "r1", wantAccess: "old", wantRefresh: "r1"},
+		{name: "refresh before expiry", expiresIn: 2 * time.Minute, refreshToken: "r1", refreshBody: `{"access_token":"new","refresh_token":"r2","expires_in":3600}`, wantRefreshes: 1, wantAccess: "new", wantRefresh: "r2"},
+		{name: "refresh keeps refresh token", expiresIn: -time.Minute, refreshToken: "r1", refreshBody: `{"access_token":"new","expires_in":3600}`, wantRefreshes: 1, wantAccess: "new", wantRefresh: "r1"},
+		{name: "expired without refresh token", expiresIn: -time.Minute, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var refreshes atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				refreshes.Add(1)
+				r.ParseForm()
+				if got := r.PostForm.Get("refresh_token"); got != tt.refreshToken {
+					t.Errorf("refresh_token = %q, want %q", got, tt.refreshToken)
+				}
+				w.Write([]byte(tt.refreshBody))
+			}))
+			defer server.Close()
+
+			tokenFile := filepath.Join(t.TempDir(), "token.json")
+			err := SaveToken(tokenFile, &Token{
+				AccessToken:  "old",
+				RefreshToken: tt.refreshToken,
+				ExpiresAt:    time.Now().Add(tt.expiresIn),
+				UserID:       "42",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			source := NewTokenSource(Config{ClientID: "id", TokenFile: tokenFile, RefreshURL: server.URL})
+			access, err := source.AccessToken()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			// Повторный вызов не обновляет токен ещё раз
+			if _, err := source.AccessToken(); err != nil {
+				t.Fatal(err)
+			}
+
+			if access != tt.wantAccess {
+				t.Errorf("access token = %q, want %q", access, tt.wantAccess)
+			}
+			if got := refreshes.Load(); got != tt.wantRefreshes {
+				t.Errorf("refreshes = %d, want %d", got, tt.wantRefreshes)
+			}
+
+			stored, err := LoadToken(tokenFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.AccessToken != tt.wantAccess || stored.RefreshToken != tt.wantRefresh || stored.UserID != "42" {
+				t.Errorf("stored token = %+v", stored)
+			}
+		})
+	}
+}
+
+func TestLoadTokenMissing(t *testing.T) {
+	_, err := LoadToken(filepath.Join(t.TempDir(), "missing.json"))
+	if err != ErrNoToken {
+		t.Errorf("err = %v, want ErrNoToken", err)
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	cfg := Config{ClientID: "id", RedirectURL: "http://localhost/cb", Scopes: []string{"files:read", "file_comments:write"}}
+	u := cfg.AuthCodeURL("xyz")
+	for _, want := range []string{DefaultAuthURL + "?", "client_id=id", "state=xyz", "response_type=code", "scope=files%3Aread%2Cfile_comments%3Awrite"} {
+		if !strings.Contains(u, want) {
+			t.Errorf("%s does not contain %s", u, want)
+		}
+	}
+}
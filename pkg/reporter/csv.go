@@ -0,0 +1,26 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/csv"
+)
+
+// csvRenderer пишет только строки комментариев; ошибки источников
+// в CSV не попадают и остаются в логе.
+type csvRenderer struct{}
+
+func (csvRenderer) Extension() string { return "csv" }
+
+func (csvRenderer) MIMEType() string { return "text/csv; charset=utf-8" }
+
+func (csvRenderer) Render(rep *Report) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(rep.Header()); err != nil {
+		return nil, err
+	}
+	if err := w.WriteAll(rep.Rows()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,182 @@
+package reporter
+
+import (
+	"log"
+	"time"
+
+	"github.com/Hikitak/figma-comment-reporter/internal/utils"
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+)
+
+type fileTarget struct {
+	Key         string
+	Alias       string
+	Tags        []string
+	ProjectID   string
+	ProjectName string
+	// Клиент с учётными данными для этого файла
+	Client *figma.Client
+
+	// Для веток: ключ и имя основного файла
+	MainKey    string
+	MainName   string
+	BranchName string
+}
+
+// FileID возвращает ключ основного файла, в том числе для веток.
+func (t fileTarget) FileID() string {
+	if t.MainKey != "" {
+		return t.MainKey
+	}
+	return t.Key
+}
+
+func (t fileTarget) BranchKey() string {
+	if t.MainKey != "" {
+		return t.Key
+	}
+	return ""
+}
+
+// resolveFiles объединяет явно заданные file_keys с файлами,
+// найденными в командах и проектах Figma.
+func (r *Reporter) resolveFiles() ([]fileTarget, []failure) {
+	var targets []fileTarget
+	var failures []failure
+	index := make(map[string]int)
+
+	for _, file := range r.Files {
+		if _, ok := index[file.Key]; ok {
+			continue
+		}
+		target := fileTarget{
+			Key:    file.Key,
+			Alias:  file.Alias,
+			Tags:   file.Tags,
+			Client: r.Client,
+		}
+		if file.Token != "" {
+			target.Client = r.Client.WithToken(file.Token)
+		}
+		index[file.Key] = len(targets)
+		targets = append(targets, target)
+	}
+	for _, key := range r.FileKeys {
+		if _, ok := index[key]; ok {
+			continue
+		}
+		index[key] = len(targets)
+		targets = append(targets, fileTarget{Key: key, Client: r.Client})
+	}
+
+	projects := make([]figma.Project, 0, len(r.ProjectIDs))
+	for _, id := range r.ProjectIDs {
+		projects = append(projects, figma.Project{ID: id})
+	}
+	for _, teamID := range r.TeamIDs {
+		teamProjects, err := r.Client.GetTeamProjects(teamID)
+		if err != nil {
+			log.Printf("Error listing projects for team %s: %v", teamID, err)
+			failures = append(failures, failure{Source: "team", ID: teamID, Err: err})
+			continue
+		}
+		projects = append(projects, teamProjects...)
+	}
+
+	seenProjects := make(map[string]bool)
+	for _, project := range projects {
+		if seenProjects[project.ID] {
+			continue
+		}
+		seenProjects[project.ID] = true
+
+		projectName, files, err := r.Client.GetProjectFiles(project.ID)
+		if err != nil {
+			log.Printf("Error listing files for project %s: %v", project.ID, err)
+			failures = append(failures, failure{Source: "project", ID: project.ID, Err: err})
+			continue
+		}
+		if projectName == "" {
+			projectName = project.Name
+		}
+
+		for _, file := range files {
+			if i, ok := index[file.Key]; ok {
+				// Явно заданный файл дополняем сведениями о проекте
+				targets[i].ProjectID = project.ID
+				targets[i].ProjectName = projectName
+				continue
+			}
+			if !matchDiscovery(r.Discovery, file) {
+				continue
+			}
+			index[file.Key] = len(targets)
+			targets = append(targets, fileTarget{
+				Key:         file.Key,
+				ProjectID:   project.ID,
+				ProjectName: projectName,
+				Client:      r.Client,
+			})
+		}
+	}
+
+	if r.Branches.Enabled {
+		targets, failures = r.addBranches(targets, failures)
+	}
+
+	return targets, failures
+}
+
+// addBranches добавляет после каждого файла его ветки. Архивные ветки
+// пропускаются, если не включён include_archived.
+func (r *Reporter) addBranches(targets []fileTarget, failures []failure) ([]fileTarget, []failure) {
+	var withBranches []fileTarget
+	seen := make(map[string]bool)
+	for _, target := range targets {
+		seen[target.Key] = true
+	}
+
+	for _, target := range targets {
+		withBranches = append(withBranches, target)
+
+		mainName, branches, err := target.Client.GetBranches(target.Key)
+		if err != nil {
+			log.Printf("Error listing branches for file %s: %v", target.Key, err)
+			failures = append(failures, failure{Source: "branches", ID: target.Key, Err: err})
+			continue
+		}
+
+		for _, branch := range branches {
+			if seen[branch.Key] || (branch.Archived && !r.Branches.IncludeArchived) {
+				continue
+			}
+			seen[branch.Key] = true
+
+			branchTarget := target
+			branchTarget.Key = branch.Key
+			branchTarget.MainKey = target.Key
+			branchTarget.MainName = mainName
+			branchTarget.BranchName = branch.Name
+			withBranches = append(withBranches, branchTarget)
+		}
+	}
+
+	return withBranches, failures
+}
+
+func matchDiscovery(filter config.DiscoveryConfig, file figma.ProjectFile) bool {
+	if len(filter.Include) > 0 && !utils.MatchAnyGlob(filter.Include, file.Name) {
+		return false
+	}
+	if utils.MatchAnyGlob(filter.Exclude, file.Name) {
+		return false
+	}
+	if filter.ModifiedWithinDays > 0 {
+		since := time.Now().AddDate(0, 0, -filter.ModifiedWithinDays)
+		if file.LastModified.Before(since) {
+			return false
+		}
+	}
+	return true
+}
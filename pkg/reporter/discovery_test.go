@@ -0,0 +1,221 @@
+package reporter
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+)
+
+func TestAddBranches(t *testing.T) {
+	tests := []struct {
+		name            string
+		fileKeys        []string
+		includeArchived bool
+		// Ключи файлов и ветки в порядке отчёта: "KEY", "BR1@KEY:Redesign"
+		want []string
+	}{
+		{name: "archived skipped", fileKeys: []string{"KEY"}, want: []string{"KEY", "BR1@KEY:Redesign"}},
+		{name: "archived included", fileKeys: []string{"KEY"}, includeArchived: true, want: []string{"KEY", "BR1@KEY:Redesign", "BR2@KEY:Old idea"}},
+		// Ветка, заданная явно, не добавляется второй раз
+		{name: "branch already listed", fileKeys: []string{"KEY", "BR1"}, want: []string{"KEY", "BR1"}},
+		{name: "file without branches", fileKeys: []string{"OTHER"}, want: []string{"OTHER"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFigma(t)
+			r := f.reporter()
+			r.FileKeys = tt.fileKeys
+			r.Branches.Enabled = true
+			r.Branches.IncludeArchived = tt.includeArchived
+
+			targets, failures := r.resolveFiles()
+			if len(failures) > 0 {
+				t.Fatalf("unexpected failures: %v", failures)
+			}
+			var got []string
+			for _, target := range targets {
+				key := target.Key
+				if target.MainKey != "" {
+					key += "@" + target.MainKey + ":" + target.BranchName
+				}
+				got = append(got, key)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("targets = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBranchFields(t *testing.T) {
+	f := newFakeFigma(t)
+	r := f.reporter("file_id", "branch_key", "branch_name", "link")
+	r.Branches.Enabled = true
+
+	entries, _, err := r.gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 6 {
+		t.Fatalf("got %d rows, want 3 for KEY and 3 for its branch", len(entries))
+	}
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"file_id", "KEY|KEY|KEY|KEY|KEY|KEY"},
+		{"branch_key", "|||BR1|BR1|BR1"},
+		{"branch_name", "|||Redesign|Redesign|Redesign"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(column(r, entries, tt.field), "|"); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.field, got, tt.want)
+		}
+	}
+
+	links := column(r, entries, "link")
+	if want := "https://www.figma.com/design/KEY?node-id=1-3#c1"; links[0] != want {
+		t.Errorf("link = %s, want %s", links[0], want)
+	}
+	if want := "https://www.figma.com/design/KEY/branch/BR1?node-id=1-3#c1"; links[3] != want {
+		t.Errorf("branch link = %s, want %s", links[3], want)
+	}
+}
+
+func TestMatchDiscovery(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		filter config.DiscoveryConfig
+		file   figma.ProjectFile
+		want   bool
+	}{
+		{"no filter", config.DiscoveryConfig{}, figma.ProjectFile{Name: "Checkout"}, true},
+		{"include matches", config.DiscoveryConfig{Include: []string{"check*"}}, figma.ProjectFile{Name: "Checkout"}, true},
+		{"include misses", config.DiscoveryConfig{Include: []string{"check*"}}, figma.ProjectFile{Name: "Onboarding"}, false},
+		{"star crosses slash", config.DiscoveryConfig{Include: []string{"web*old"}}, figma.ProjectFile{Name: "Web / Old"}, true},
+		{"exclude wins", config.DiscoveryConfig{Include: []string{"*"}, Exclude: []string{"*archive*"}}, figma.ProjectFile{Name: "Old Archive"}, false},
+		{"recent", config.DiscoveryConfig{ModifiedWithinDays: 7}, figma.ProjectFile{Name: "a", LastModified: now.AddDate(0, 0, -6)}, true},
+		{"stale", config.DiscoveryConfig{ModifiedWithinDays: 7}, figma.ProjectFile{Name: "a", LastModified: now.AddDate(0, 0, -8)}, false},
+	}
+	for _, tt := range tests {
+		if got := matchDiscovery(tt.filter, tt.file); got != tt.want {
+			t.Errorf("%s: matchDiscovery = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiscovery(t *testing.T) {
+	f := newFakeFigma(t)
+	r := f.reporter("file_id", "project_name")
+	r.FileKeys = nil
+	r.Files = []config.FileConfig{{Key: "KEY", Token: "own"}}
+	r.TeamIDs = []string{"T1", "MISSING"}
+	// P1 есть и в команде T1: файлы проекта запрашиваются один раз
+	r.ProjectIDs = []string{"P1", "MISSING"}
+	r.Discovery = config.DiscoveryConfig{Exclude: []string{"archive*"}, ModifiedWithinDays: 30}
+
+	targets, _ := r.resolveFiles()
+	listings := len(f.queries("project_files"))
+	if targets[0].Key != "KEY" || targets[0].Client.Token != "own" {
+		t.Errorf("explicit file = %s with token %q, want KEY with its own token", targets[0].Key, targets[0].Client.Token)
+	}
+
+	entries, failures, err := r.gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Явно заданный файл получает имя проекта, в котором найден
+	var files []string
+	projects := column(r, entries, "project_name")
+	for i, key := range column(r, entries, "file_id") {
+		files = append(files, key+" "+projects[i])
+	}
+	if want := "KEY Project P1|D1 Project P1|D3 Project P2"; strings.Join(slices.Compact(files), "|") != want {
+		t.Errorf("files = %q, want %s", slices.Compact(files), want)
+	}
+
+	var sources []string
+	for _, failure := range failures {
+		sources = append(sources, failure.Source+" "+failure.ID)
+	}
+	if want := "team MISSING|project MISSING"; strings.Join(sources, "|") != want {
+		t.Errorf("failures = %q, want %s", sources, want)
+	}
+	if got := len(f.queries("project_files")) - listings; got != 3 {
+		t.Errorf("project file listings = %d, want 3 (P1, MISSING, P2)", got)
+	}
+}
+
+func TestFileConfig(t *testing.T) {
+	files := []config.FileConfig{{Key: "KEY2", Token: "own", Alias: "Checkout web", Tags: []string{"web", "partner"}}}
+
+	tests := []struct {
+		name    string
+		filters []config.FilterRule
+		want    string
+	}{
+		{name: "no filters", want: "KEY2:Checkout web:web, partner|KEY::"},
+		{name: "alias", filters: []config.FilterRule{{Field: "file_alias", Value: "Checkout web"}}, want: "KEY2:Checkout web:web, partner"},
+		{name: "tag", filters: []config.FilterRule{{Field: "file_tags", Op: "has", Value: "partner"}}, want: "KEY2:Checkout web:web, partner"},
+		{name: "tag ignores case", filters: []config.FilterRule{{Field: "file_tags", Op: "has", Value: "Web"}}, want: "KEY2:Checkout web:web, partner"},
+		{name: "no such tag", filters: []config.FilterRule{{Field: "file_tags", Op: "has", Value: "mobile"}}, want: ""},
+		{name: "untagged", filters: []config.FilterRule{{Field: "file_tags", Value: ""}}, want: "KEY::"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFigma(t)
+			r := f.reporter("file_id", "file_alias", "file_tags")
+			r.Files = files
+			r.Filters = tt.filters
+
+			entries, _, err := r.gather()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var rows []string
+			alias, tags := column(r, entries, "file_alias"), column(r, entries, "file_tags")
+			for i, key := range column(r, entries, "file_id") {
+				rows = append(rows, key+":"+alias[i]+":"+tags[i])
+			}
+			if got := strings.Join(slices.Compact(rows), "|"); got != tt.want {
+				t.Errorf("files = %s, want %s", got, tt.want)
+			}
+
+			// Файл со своим токеном запрашивается только с ним
+			if tokens := f.tokens("KEY"); strings.Join(tokens, ",") != "token" {
+				t.Errorf("KEY tokens = %q, want token", tokens)
+			}
+			if tokens := f.tokens("KEY2"); strings.Join(tokens, ",") != "own" {
+				t.Errorf("KEY2 tokens = %q, want own", tokens)
+			}
+		})
+	}
+}
+
+func TestSelectCommentToken(t *testing.T) {
+	f := newFakeFigma(t)
+	r := f.reporter("node_name")
+	r.Files = []config.FileConfig{{Key: "KEY2", Token: "own"}}
+
+	if _, err := r.SelectComment("KEY2", "c1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.SelectComment("KEY", "c1"); err != nil {
+		t.Fatal(err)
+	}
+	if tokens := f.tokens("KEY2"); strings.Join(tokens, ",") != "own" {
+		t.Errorf("KEY2 tokens = %q, want own", tokens)
+	}
+	if tokens := f.tokens("KEY"); strings.Join(tokens, ",") != "token" {
+		t.Errorf("KEY tokens = %q, want token", tokens)
+	}
+}
@@ -0,0 +1,41 @@
+package reporter
+
+import (
+	"strconv"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+	"github.com/tealeg/xlsx"
+)
+
+// failure — файл, проект или команда, данные которых не попали в отчёт.
+type failure struct {
+	Source string
+	ID     string
+	Err    error
+}
+
+func writeErrorsSheet(file *xlsx.File, failures []failure) error {
+	sheet, err := file.AddSheet("Errors")
+	if err != nil {
+		return err
+	}
+
+	headerRow := sheet.AddRow()
+	for _, title := range []string{"Source", "ID", "Status", "Reason", "Error"} {
+		headerRow.AddCell().Value = title
+	}
+
+	for _, f := range failures {
+		row := sheet.AddRow()
+		row.AddCell().Value = f.Source
+		row.AddCell().Value = f.ID
+		status := ""
+		if code := figma.StatusCode(f.Err); code != 0 {
+			status = strconv.Itoa(code)
+		}
+		row.AddCell().Value = status
+		row.AddCell().Value = figma.Reason(f.Err)
+		row.AddCell().Value = f.Err.Error()
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package reporter
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+)
+
+// fileData — сведения о файле, не зависящие от комментариев. Пока версия
+// файла не меняется, они берутся из кэша без запросов узлов и дерева.
+type fileData struct {
+	Name  string                 `json:"name"`
+	Nodes map[string]*figma.Node `json:"nodes"`
+	Paths map[string][]string    `json:"paths,omitempty"`
+	// Метаданные запрашиваются на каждом запуске и в кэш не попадают
+	Meta *figma.FileMeta `json:"-"`
+}
+
+// fileMetaFields — поля отчёта, которым нужны метаданные файла.
+var fileMetaFields = []string{"file_last_modified", "editor_type", "file_thumbnail_url", "file_role", "file_link"}
+
+func (r *Reporter) loadFileData(client *figma.Client, fileKey string, nodeIDs []string) (*fileData, error) {
+	data := &fileData{}
+	changed := false
+
+	// Метаданные нужны полям отчёта и кэшу. Без них файл всё равно
+	// попадает в отчёт: поля метаданных пустые, кэш не используется
+	var meta *figma.FileMeta
+	withMeta := r.usesField(fileMetaFields...)
+	if withMeta || r.Cache != nil {
+		var err error
+		meta, err = client.GetFileMeta(fileKey)
+		if err != nil {
+			log.Printf("Error getting metadata for file %s, metadata fields and cache skipped: %v", fileKey, err)
+		}
+	}
+	if withMeta {
+		data.Meta = meta
+	}
+
+	var version string
+	if r.Cache != nil && meta != nil {
+		if version = cacheVersion(meta); version == "" {
+			log.Printf("File %s has no version or modification time, cache skipped", fileKey)
+		} else {
+			if _, err := r.Cache.Load(fileKey, version, data); err != nil {
+				log.Printf("Error reading cache for file %s: %v", fileKey, err)
+			}
+			if data.Name == "" {
+				data.Name = meta.Name
+				changed = true
+			}
+		}
+	}
+	if data.Nodes == nil {
+		data.Nodes = make(map[string]*figma.Node)
+	}
+
+	var missing []string
+	for _, id := range nodeIDs {
+		if _, ok := data.Nodes[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		nodesResponse, err := client.GetFileNodes(fileKey, missing)
+		if err != nil {
+			return nil, fmt.Errorf("getting nodes: %w", err)
+		}
+		if data.Name == "" {
+			data.Name = nodesResponse.Name
+		}
+		for _, id := range missing {
+			// Узел, не вернувшийся в ответе, запоминаем как удалённый
+			data.Nodes[id] = nodesResponse.Nodes[id]
+		}
+		changed = true
+	}
+
+	if data.Name == "" {
+		file, err := client.GetFile(fileKey, 1)
+		if err != nil {
+			return nil, fmt.Errorf("getting file: %w", err)
+		}
+		data.Name = file.Name
+		changed = true
+	}
+
+	// Дерево файла нужно только для полей с путём узла и загружается один раз
+	if r.usesField("page_name", "top_frame_name", "node_path") || r.Markdown.GroupByPage {
+		if data.Paths == nil {
+			data.Paths = make(map[string][]string)
+		}
+		var withoutPath []string
+		for id, node := range data.Nodes {
+			if _, ok := data.Paths[id]; !ok && node != nil {
+				withoutPath = append(withoutPath, id)
+			}
+		}
+		if len(withoutPath) > 0 {
+			// Запрашиваются только ветки дерева до узлов с комментариями
+			file, err := client.GetFileTree(fileKey, withoutPath)
+			if err != nil {
+				return nil, fmt.Errorf("getting file tree: %w", err)
+			}
+			tree := figma.NewTree(&file.Document)
+			for _, id := range withoutPath {
+				path := []string{}
+				for _, ancestor := range tree.Path(id) {
+					path = append(path, ancestor.Name)
+				}
+				data.Paths[id] = path
+			}
+			changed = true
+		}
+	}
+
+	if r.Cache != nil && version != "" && changed {
+		if err := r.Cache.Store(fileKey, version, data); err != nil {
+			log.Printf("Error writing cache for file %s: %v", fileKey, err)
+		}
+	}
+
+	return data, nil
+}
+
+// cacheVersion возвращает версию, с которой связана запись кэша: номер
+// версии файла или, если его нет, время последнего изменения. Пустая
+// строка — записи не с чем сверять, кэш не используется.
+func cacheVersion(meta *figma.FileMeta) string {
+	if meta.Version != "" {
+		return meta.Version
+	}
+	if !meta.LastTouchedAt.IsZero() {
+		return meta.LastTouchedAt.Format(time.RFC3339Nano)
+	}
+	return ""
+}
@@ -0,0 +1,180 @@
+package reporter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/cache"
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+)
+
+func TestCacheVersion(t *testing.T) {
+	touched := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		meta figma.FileMeta
+		want string
+	}{
+		{"version", figma.FileMeta{Version: "42", LastTouchedAt: touched}, "42"},
+		{"modification time", figma.FileMeta{LastTouchedAt: touched}, "2024-02-01T00:00:00Z"},
+		{"nothing to compare", figma.FileMeta{}, ""},
+	}
+	for _, tt := range tests {
+		if got := cacheVersion(&tt.meta); got != tt.want {
+			t.Errorf("%s: cacheVersion = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFileDataCache(t *testing.T) {
+	tests := []struct {
+		name string
+		meta string
+		// Запросы узлов и дерева за два запуска
+		wantNodes, wantTree int
+	}{
+		{name: "versioned file", meta: `{"file":{"name":"Checkout","version":"42"}}`, wantNodes: 1, wantTree: 1},
+		{name: "modification time only", meta: `{"file":{"name":"Checkout","last_touched_at":"2024-02-01T00:00:00Z"}}`, wantNodes: 1, wantTree: 1},
+		{name: "no version", meta: `{"file":{"name":"Checkout"}}`, wantNodes: 2, wantTree: 2},
+		{name: "metadata error", meta: "", wantNodes: 2, wantTree: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFigma(t)
+			f.meta = tt.meta
+			dir := t.TempDir()
+
+			for run := 0; run < 2; run++ {
+				r := f.reporter("node_name", "node_path")
+				r.Cache = cache.New(dir, 0)
+				entries, failures, err := r.gather()
+				if err != nil || len(failures) > 0 {
+					t.Fatalf("run %d: %v %v", run, err, failures)
+				}
+				if got := column(r, entries, "node_path"); got[0] != "Page 1 / Payment modal / Button" {
+					t.Errorf("run %d: node_path = %q", run, got)
+				}
+			}
+
+			if got := f.count("nodes"); got != tt.wantNodes {
+				t.Errorf("node requests = %d, want %d", got, tt.wantNodes)
+			}
+			if got := f.count("file"); got != tt.wantTree {
+				t.Errorf("tree requests = %d, want %d", got, tt.wantTree)
+			}
+		})
+	}
+}
+
+func TestThumbnailCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		refresh bool
+		// Скачиваний за два запуска: узлов с комментариями два
+		wantDownloads int
+	}{
+		{name: "cached", wantDownloads: 2},
+		{name: "no-cache", refresh: true, wantDownloads: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFigma(t)
+			dir := t.TempDir()
+
+			for run := 0; run < 2; run++ {
+				r := f.reporter("thumbnail")
+				r.Thumbnails.CacheDir = dir
+				r.RefreshThumbnails = tt.refresh
+				if _, err := r.Collect(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := f.count("download"); got != tt.wantDownloads {
+				t.Errorf("downloads = %d, want %d", got, tt.wantDownloads)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "KEY", "1-3@1x.png")); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestFileMetaFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		meta       string
+		wantEditor string
+		wantRole   string
+		wantLink   string
+	}{
+		{name: "metadata", meta: `{"file":{"name":"Checkout","version":"42","editorType":"figma","role":"editor"}}`, wantEditor: "figma", wantRole: "editor", wantLink: "https://www.figma.com/design/KEY"},
+		{name: "link from metadata", meta: `{"file":{"name":"Checkout","url":"https://www.figma.com/design/KEY/Checkout"}}`, wantLink: "https://www.figma.com/design/KEY/Checkout"},
+		// Ошибка метаданных не мешает комментариям попасть в отчёт
+		{name: "metadata error", meta: "", wantLink: "https://www.figma.com/design/KEY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFigma(t)
+			f.meta = tt.meta
+			r := f.reporter("editor_type", "file_role", "file_link")
+
+			entries, failures, err := r.gather()
+			if err != nil || len(failures) > 0 {
+				t.Fatalf("%v %v", err, failures)
+			}
+			if len(entries) != 3 {
+				t.Fatalf("got %d rows, want 3", len(entries))
+			}
+			e := entries[0]
+			for field, want := range map[string]string{"editor_type": tt.wantEditor, "file_role": tt.wantRole, "file_link": tt.wantLink} {
+				if got := r.getFieldValue(e, config.ReportField{Name: field}); got != want {
+					t.Errorf("%s = %q, want %q", field, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFileTreeRequest(t *testing.T) {
+	tests := []struct {
+		field string
+		// Ожидаемые параметры ids запросов дерева
+		want []string
+	}{
+		{"node_name", nil},
+		{"node_path", []string{"1:3,1:4"}},
+		{"page_name", []string{"1:3,1:4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			f := newFakeFigma(t)
+			r := f.reporter(tt.field)
+			if _, _, err := r.gather(); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, query := range f.queries("file") {
+				// Запрос имени файла с depth=1 дерево не загружает
+				if query.Get("depth") == "1" {
+					continue
+				}
+				if !query.Has("ids") {
+					t.Errorf("whole document requested: %v", query)
+				}
+				got = append(got, query.Get("ids"))
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("tree requests = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
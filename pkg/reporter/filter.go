@@ -0,0 +1,120 @@
+package reporter
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Hikitak/figma-comment-reporter/internal/utils"
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+)
+
+func validateFilters(filters []config.FilterRule) error {
+	for _, filter := range filters {
+		switch filter.Op {
+		case "", "=", "!=", ">", ">=", "<", "<=", "contains", "glob", "has":
+		default:
+			return fmt.Errorf("unknown filter operator %q for field %s", filter.Op, filter.Field)
+		}
+	}
+	return nil
+}
+
+func (r *Reporter) filterEntries(entries []entry) []entry {
+	if len(r.Filters) == 0 {
+		return entries
+	}
+
+	filtered := entries[:0]
+	for _, e := range entries {
+		if r.matchFilters(e) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// matchFilters проверяет строку по всем фильтрам, кроме фильтров
+// по полям skipFields.
+func (r *Reporter) matchFilters(e entry, skipFields ...string) bool {
+	for _, filter := range r.Filters {
+		if slices.Contains(skipFields, filter.Field) {
+			continue
+		}
+		value := r.getFieldValue(e, config.ReportField{Name: filter.Field})
+		if !matchFilter(filter, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchFilter(filter config.FilterRule, value string) bool {
+	switch filter.Op {
+	case "", "=":
+		return compareValues(value, filter.Value) == 0
+	case "!=":
+		return compareValues(value, filter.Value) != 0
+	case ">":
+		return compareValues(value, filter.Value) > 0
+	case ">=":
+		return compareValues(value, filter.Value) >= 0
+	case "<":
+		return compareValues(value, filter.Value) < 0
+	case "<=":
+		return compareValues(value, filter.Value) <= 0
+	case "contains":
+		return strings.Contains(strings.ToLower(value), strings.ToLower(filter.Value))
+	case "glob":
+		return utils.MatchGlob(filter.Value, value)
+	case "has":
+		// Значение — список через запятую, например file_tags
+		for _, item := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(item), filter.Value) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+func (r *Reporter) sortEntries(entries []entry) {
+	if len(r.Sort) == 0 {
+		return
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		for _, rule := range r.Sort {
+			field := config.ReportField{Name: rule.Field}
+			c := compareValues(r.getFieldValue(entries[i], field), r.getFieldValue(entries[j], field))
+			if c == 0 {
+				continue
+			}
+			if rule.Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+// compareValues сравнивает числа как числа, остальное — как строки.
+// Даты без формата выводятся в RFC 3339 и сравниваются корректно.
+func compareValues(a, b string) int {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
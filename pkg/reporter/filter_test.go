@@ -0,0 +1,125 @@
+package reporter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+)
+
+func TestMatchFilter(t *testing.T) {
+	tests := []struct {
+		op, filter, value string
+		want              bool
+	}{
+		{"", "open", "open", true},
+		{"=", "open", "resolved", false},
+		{"!=", "open", "resolved", true},
+		{">", "9", "10", true},
+		{">", "b", "a", false},
+		{">=", "2024-01-02", "2024-01-02T10:00:00Z", true},
+		{"<", "10", "9", true},
+		{"<=", "3", "3", true},
+		{"contains", "BUTTON", "Fix the button", true},
+		{"contains", "card", "Fix the button", false},
+		{"glob", "Check*", "Checkout", true},
+		{"glob", "*archive*", "Checkout", false},
+		{"has", "partner", "web, Partner", true},
+		{"has", "part", "web, partner", false},
+		{"unknown", "x", "x", false},
+	}
+	for _, tt := range tests {
+		rule := config.FilterRule{Field: "f", Op: tt.op, Value: tt.filter}
+		if got := matchFilter(rule, tt.value); got != tt.want {
+			t.Errorf("%q %s %q = %v, want %v", tt.value, tt.op, tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestValidateFilters(t *testing.T) {
+	tests := []struct {
+		op      string
+		wantErr bool
+	}{
+		{"", false},
+		{"contains", false},
+		{"has", false},
+		{"~", true},
+	}
+	for _, tt := range tests {
+		err := validateFilters([]config.FilterRule{{Field: "status", Op: tt.op}})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("op %q: err = %v, want error %v", tt.op, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFiltersAndSort(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters []config.FilterRule
+		sort    []config.SortRule
+		want    string
+	}{
+		{name: "no rules", want: "1|2|3"},
+		{name: "open only", filters: []config.FilterRule{{Field: "status", Value: "open"}}, want: "1|3"},
+		{name: "author and node", filters: []config.FilterRule{{Field: "author", Value: "bob"}, {Field: "node_name", Value: "Card"}}, want: "2"},
+		{name: "newest first", sort: []config.SortRule{{Field: "created_at", Desc: true}}, want: "3|2|1"},
+		{name: "node then number desc", sort: []config.SortRule{{Field: "node_name"}, {Field: "comment_number", Desc: true}}, want: "1|3|2"},
+		{name: "votes", sort: []config.SortRule{{Field: "reaction_count", Desc: true}, {Field: "comment_number"}}, want: "1|3|2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFigma(t)
+			r := f.reporter("comment_number")
+			r.Filters = tt.filters
+			r.Sort = tt.sort
+
+			entries, _, err := r.gather()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(column(r, entries, "comment_number"), "|"); got != tt.want {
+				t.Errorf("comments = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadReactions(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters []config.FilterRule
+		// c4 приходит с реакциями в списке комментариев и не запрашивается
+		wantCalls int
+		wantVotes string
+	}{
+		{name: "all threads", wantCalls: 2, wantVotes: "2|0|1"},
+		{name: "filtered threads skipped", filters: []config.FilterRule{{Field: "status", Value: "open"}}, wantCalls: 1, wantVotes: "2|1"},
+		{name: "filter on reactions still fetches", filters: []config.FilterRule{{Field: "reaction_count", Op: ">", Value: "0"}}, wantCalls: 2, wantVotes: "2|1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFigma(t)
+			r := f.reporter("reaction_count")
+			r.Client.NodeConcurrency = 1
+			r.Filters = tt.filters
+
+			entries, _, err := r.gather()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := f.count("reactions"); got != tt.wantCalls {
+				t.Errorf("reaction requests = %d, want %d", got, tt.wantCalls)
+			}
+			if got := strings.Join(column(r, entries, "reaction_count"), "|"); got != tt.wantVotes {
+				t.Errorf("votes = %s, want %s", got, tt.wantVotes)
+			}
+			if f.maxActive > 1 {
+				t.Errorf("%d concurrent requests, want at most 1", f.maxActive)
+			}
+		})
+	}
+}
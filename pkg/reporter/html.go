@@ -0,0 +1,164 @@
+package reporter
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/base64"
+	"html/template"
+	"log"
+	"mime"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+)
+
+//go:embed templates/report.html
+var htmlTemplateText string
+
+var htmlTemplate = template.Must(template.New("report").Parse(htmlTemplateText))
+
+// htmlRenderer пишет один HTML-файл со встроенными стилями, скриптом
+// и миниатюрами, чтобы отчёт открывался без сети и вложений.
+type htmlRenderer struct{}
+
+func (htmlRenderer) Extension() string { return "html" }
+
+func (htmlRenderer) MIMEType() string { return "text/html; charset=utf-8" }
+
+type htmlPage struct {
+	Generated string
+	Headers   []string
+	Rows      []htmlRow
+	Authors   []string
+	Files     []string
+	Failures  []htmlFailure
+}
+
+// htmlRow хранит значения для фильтров отдельно от колонок: автор, статус
+// и файл доступны в фильтрах, даже если их нет среди полей отчёта.
+type htmlRow struct {
+	Status string
+	Author string
+	File   string
+	Cells  []htmlCell
+}
+
+type htmlCell struct {
+	Text string
+	// Значение без пользовательского формата дат для сортировки
+	SortKey string
+	Link    string
+	// Текст ссылки, если он отличается от значения
+	LinkText string
+	Image    template.URL
+	Thread   []htmlComment
+	Replies  int
+}
+
+type htmlComment struct {
+	Author  string
+	Time    string
+	Message string
+}
+
+type htmlFailure struct {
+	Source string
+	ID     string
+	Status string
+	Error  string
+}
+
+func (htmlRenderer) Render(rep *Report) ([]byte, error) {
+	page := htmlPage{Generated: time.Now().Format("2006-01-02 15:04")}
+	page.Headers = rep.Header()
+
+	authors := make(map[string]bool)
+	files := make(map[string]bool)
+	images := make(map[string]template.URL)
+	for _, e := range rep.entries {
+		row := htmlRow{
+			Status: rep.reporter.getFieldValue(e, config.ReportField{Name: "status"}),
+			Author: e.Thread.Root.User.Handle,
+			File:   e.FileName,
+		}
+		authors[row.Author] = true
+		files[row.File] = true
+
+		for _, field := range rep.Fields() {
+			cell := htmlCell{
+				Text:    rep.reporter.getFieldValue(e, field),
+				SortKey: rep.reporter.getFieldValue(e, config.ReportField{Name: field.Name}),
+			}
+			switch {
+			case field.Name == "thumbnail":
+				if e.Thumbnail != "" {
+					if _, ok := images[e.Thumbnail]; !ok {
+						images[e.Thumbnail] = imageDataURL(e.Thumbnail)
+					}
+					cell.Image = images[e.Thumbnail]
+				}
+			case field.Name == "thread":
+				for _, comment := range e.Thread.Comments() {
+					cell.Thread = append(cell.Thread, htmlComment{
+						Author:  comment.User.Handle,
+						Time:    formatTime(comment.CreatedAt, field.Format),
+						Message: comment.Message,
+					})
+				}
+				cell.Replies = e.Thread.ReplyCount()
+			case field.Name == "link" || field.Name == "file_link":
+				cell.Link = cell.Text
+				cell.LinkText = "Open in Figma"
+			case strings.HasPrefix(cell.Text, "https://") || strings.HasPrefix(cell.Text, "http://"):
+				cell.Link = cell.Text
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		page.Rows = append(page.Rows, row)
+	}
+	page.Authors = sortedKeys(authors)
+	page.Files = sortedKeys(files)
+
+	for _, f := range rep.failures {
+		hf := htmlFailure{Source: f.Source, ID: f.ID, Error: f.Err.Error()}
+		if code := figma.StatusCode(f.Err); code != 0 {
+			hf.Status = strconv.Itoa(code)
+		}
+		page.Failures = append(page.Failures, hf)
+	}
+
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, page); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// imageDataURL встраивает миниатюру из кэша в страницу.
+func imageDataURL(path string) template.URL {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("Error embedding thumbnail %s: %v", path, err)
+		return ""
+	}
+	mimeType := mime.TypeByExtension(filepath.Ext(path))
+	if mimeType == "" {
+		mimeType = "image/png"
+	}
+	return template.URL("data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data))
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,55 @@
+package reporter
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+)
+
+func TestHTMLRenderer(t *testing.T) {
+	f := newFakeFigma(t)
+	r := f.reporter("comment_number", "message", "thumbnail", "link")
+	r.Fields = append(r.Fields, config.ReportField{Name: "author", Display: `<script>alert("x")</script>`})
+	r.Thumbnails.CacheDir = t.TempDir()
+	r.FileKeys = []string{"KEY", "MISSING"}
+
+	rep, err := r.Collect(htmlRenderer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := htmlRenderer{}.Render(rep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(data)
+
+	tests := []struct {
+		name string
+		want string
+		// true — строки не должно быть на странице
+		absent bool
+	}{
+		{name: "message", want: "Поправить отступ"},
+		{name: "author filter", want: "кира"},
+		{name: "file filter", want: "Checkout"},
+		{name: "link", want: `href="https://www.figma.com/`},
+		{name: "thumbnail embedded", want: `src="data:image/png;base64,`},
+		{name: "failure", want: "MISSING"},
+		{name: "header escaped", want: "&lt;script&gt;"},
+		{name: "no raw header", want: `<script>alert`, absent: true},
+		{name: "no cache paths", want: r.Thumbnails.CacheDir, absent: true},
+	}
+	for _, tt := range tests {
+		if got := strings.Contains(page, tt.want); got == tt.absent {
+			t.Errorf("%s: contains %q = %v", tt.name, tt.want, got)
+		}
+	}
+
+	// Страница открывается без сети: внешние ресурсы не подключаются
+	external := regexp.MustCompile(`<(script|link|img)[^>]+(src|href)="https?:`)
+	if m := external.FindString(page); m != "" {
+		t.Errorf("external resource: %s", m)
+	}
+}
@@ -0,0 +1,96 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+)
+
+// jsonRenderer пишет объект {"comments": [...], "errors": [...]}. Ключи
+// строк — имена полей, значения в том же виде, что и в колонках XLSX.
+type jsonRenderer struct{}
+
+func (jsonRenderer) Extension() string { return "json" }
+
+func (jsonRenderer) MIMEType() string { return "application/json" }
+
+func (jsonRenderer) Render(rep *Report) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`{"comments":[`)
+	for i, e := range rep.entries {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := writeJSONRow(&buf, rep, e); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteString(`],"errors":`)
+
+	type jsonFailure struct {
+		Source string `json:"source"`
+		ID     string `json:"id"`
+		Status string `json:"status,omitempty"`
+		Reason string `json:"reason,omitempty"`
+		Error  string `json:"error"`
+	}
+	failures := make([]jsonFailure, 0, len(rep.failures))
+	for _, f := range rep.failures {
+		jf := jsonFailure{Source: f.Source, ID: f.ID, Reason: figma.Reason(f.Err), Error: f.Err.Error()}
+		if code := figma.StatusCode(f.Err); code != 0 {
+			jf.Status = strconv.Itoa(code)
+		}
+		failures = append(failures, jf)
+	}
+	data, err := json.Marshal(failures)
+	if err != nil {
+		return nil, err
+	}
+	buf.Write(data)
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+// ndjsonRenderer пишет по одному JSON-объекту на строку без ошибок
+// источников, чтобы каждую строку можно было обработать отдельно.
+type ndjsonRenderer struct{}
+
+func (ndjsonRenderer) Extension() string { return "ndjson" }
+
+func (ndjsonRenderer) MIMEType() string { return "application/x-ndjson" }
+
+func (ndjsonRenderer) Render(rep *Report) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, e := range rep.entries {
+		if err := writeJSONRow(&buf, rep, e); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// writeJSONRow пишет строку отчёта объектом с ключами в порядке колонок.
+func writeJSONRow(buf *bytes.Buffer, rep *Report, e entry) error {
+	buf.WriteByte('{')
+	for i, field := range rep.Fields() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field.Name)
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(rep.reporter.getFieldValue(e, field))
+		if err != nil {
+			return err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return nil
+}
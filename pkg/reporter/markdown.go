@@ -0,0 +1,150 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+)
+
+// markdownRenderer пишет таблицы GitHub Flavored Markdown: раздел на файл
+// и, если включено Markdown.GroupByPage, подраздел на страницу.
+type markdownRenderer struct{}
+
+func (markdownRenderer) Extension() string { return "md" }
+
+func (markdownRenderer) MIMEType() string { return "text/markdown; charset=utf-8" }
+
+// markdownGroup — строки одного раздела в порядке сортировки отчёта.
+type markdownGroup struct {
+	Title   string
+	Entries []entry
+}
+
+func (markdownRenderer) Render(rep *Report) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("# Figma Comments Report\n\n")
+
+	files := groupEntries(rep.entries, fileGroupKey, fileTitle)
+	fmt.Fprintf(&buf, "Generated %s: %d comments in %d files.\n",
+		time.Now().Format("2006-01-02 15:04"), len(rep.entries), len(files))
+
+	for _, file := range files {
+		fmt.Fprintf(&buf, "\n## %s\n", escapeMarkdownHeading(file.Title))
+		if !rep.reporter.Markdown.GroupByPage {
+			buf.WriteByte('\n')
+			writeMarkdownTable(&buf, rep, file.Entries)
+			continue
+		}
+		pages := groupEntries(file.Entries, pageTitle, pageTitle)
+		for _, page := range pages {
+			fmt.Fprintf(&buf, "\n### %s\n\n", escapeMarkdownHeading(page.Title))
+			writeMarkdownTable(&buf, rep, page.Entries)
+		}
+	}
+
+	if len(rep.failures) > 0 {
+		buf.WriteString("\n## Errors\n\n")
+		for _, f := range rep.failures {
+			status := ""
+			if code := figma.StatusCode(f.Err); code != 0 {
+				status = " (HTTP " + strconv.Itoa(code) + ")"
+			}
+			fmt.Fprintf(&buf, "- %s `%s`%s: %s\n", f.Source, f.ID, status, escapeMarkdownCell(f.Err.Error()))
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// fileTitle — заголовок раздела файла; ветка указывается рядом с именем
+// основного файла.
+func fileTitle(e entry) string {
+	if e.File.BranchName != "" {
+		return fmt.Sprintf("%s (branch: %s)", e.FileName, e.File.BranchName)
+	}
+	return e.FileName
+}
+
+// fileGroupKey различает файлы с одинаковыми именами; у ветки свой ключ.
+func fileGroupKey(e entry) string {
+	return e.File.Key
+}
+
+func pageTitle(e entry) string {
+	if len(e.Path) > 0 {
+		return e.Path[0]
+	}
+	return "(no page)"
+}
+
+// groupEntries делит строки на разделы по key; заголовок раздела берётся
+// из title первой строки.
+func groupEntries(entries []entry, key, title func(entry) string) []markdownGroup {
+	var groups []markdownGroup
+	index := make(map[string]int)
+	for _, e := range entries {
+		k := key(e)
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, markdownGroup{Title: title(e)})
+		}
+		groups[i].Entries = append(groups[i].Entries, e)
+	}
+	return groups
+}
+
+func writeMarkdownTable(buf *bytes.Buffer, rep *Report, entries []entry) {
+	header := rep.Header()
+	for i := range header {
+		header[i] = escapeMarkdownCell(header[i])
+	}
+	buf.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	buf.WriteString(strings.Repeat("| --- ", len(header)) + "|\n")
+
+	for _, e := range entries {
+		cells := make([]string, 0, len(header))
+		for _, field := range rep.Fields() {
+			value := rep.reporter.getFieldValue(e, field)
+			switch {
+			case field.Name == "thumbnail":
+				// Файл из кэша в Markdown не встроить, картинка показывается
+				// по ссылке Figma, если она запрошена полем thumbnail_url
+				if e.ThumbnailURL != "" {
+					value = fmt.Sprintf("![%s](%s)", escapeMarkdownCell(e.NodeName), e.ThumbnailURL)
+				}
+			case field.Name == "link" || field.Name == "file_link":
+				if value != "" {
+					value = fmt.Sprintf("[Open in Figma](%s)", value)
+				}
+			default:
+				value = escapeMarkdownCell(value)
+			}
+			cells = append(cells, value)
+		}
+		buf.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+}
+
+var markdownCellReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	"|", `\|`,
+	"\r\n", "<br>",
+	"\n", "<br>",
+	"\r", "<br>",
+)
+
+// escapeMarkdownCell защищает разметку таблицы: вертикальная черта
+// экранируется, переводы строк заменяются на <br>.
+func escapeMarkdownCell(s string) string {
+	return markdownCellReplacer.Replace(s)
+}
+
+func escapeMarkdownHeading(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ", "#", `\#`).Replace(s)
+}
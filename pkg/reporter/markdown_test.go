@@ -0,0 +1,72 @@
+package reporter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownRenderer(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       []string
+		groupByPage bool
+		// Число вхождений каждой строки в отчёт
+		want map[string]int
+	}{
+		{
+			name:  "one file",
+			files: []string{"KEY"},
+			want: map[string]int{
+				"\n## Checkout\n":                  1,
+				`| Fix \| the  button<br>please |`: 1,
+				"in 1 files.":                      1,
+			},
+		},
+		{
+			// Файлы с одинаковым именем не сливаются в один раздел
+			name:  "same name",
+			files: []string{"KEY", "OTHER"},
+			want: map[string]int{
+				"\n## Checkout\n": 2,
+				"in 2 files.":     1,
+				"| Wrong color |": 2,
+			},
+		},
+		{
+			name:        "by page",
+			files:       []string{"KEY"},
+			groupByPage: true,
+			want: map[string]int{
+				"\n## Checkout\n":  1,
+				"\n### Page 1\n\n": 1,
+			},
+		},
+		{
+			name:  "failure",
+			files: []string{"KEY", "MISSING"},
+			want: map[string]int{
+				"\n## Errors\n":        1,
+				"`MISSING` (HTTP 404)": 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFigma(t)
+			r := f.reporter("message")
+			r.FileKeys = tt.files
+			r.Markdown.GroupByPage = tt.groupByPage
+
+			data, err := r.Generate(markdownRenderer{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for s, want := range tt.want {
+				if got := strings.Count(string(data), s); got != want {
+					t.Errorf("%q occurs %d times, want %d\n%s", s, got, want, data)
+				}
+			}
+		})
+	}
+}
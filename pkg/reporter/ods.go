@@ -0,0 +1,240 @@
+package reporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+)
+
+// odsRenderer пишет таблицу OpenDocument с теми же листами, что и XLSX:
+// «Comments» с миниатюрами в ячейках и «Errors», если были сбои.
+type odsRenderer struct{}
+
+const odsMIMEType = "application/vnd.oasis.opendocument.spreadsheet"
+
+func (odsRenderer) Extension() string { return "ods" }
+
+func (odsRenderer) MIMEType() string { return odsMIMEType }
+
+const odsNamespaces = `xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"` +
+	` xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0"` +
+	` xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"` +
+	` xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0"` +
+	` xmlns:draw="urn:oasis:names:tc:opendocument:xmlns:drawing:1.0"` +
+	` xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0"` +
+	` xmlns:svg="urn:oasis:names:tc:opendocument:xmlns:svg-compatible:1.0"` +
+	` xmlns:xlink="http://www.w3.org/1999/xlink"` +
+	` office:version="1.2"`
+
+func (odsRenderer) Render(rep *Report) ([]byte, error) {
+	var images []cellImage
+	var rows strings.Builder
+	var rowStyles strings.Builder
+	thumbnailColumn := -1
+
+	writeODSRow(&rows, "ceHeader", rep.Header())
+	for i, e := range rep.entries {
+		var cells strings.Builder
+		rowStyle := ""
+		for col, field := range rep.Fields() {
+			value := rep.reporter.getFieldValue(e, field)
+
+			if field.Name == "thumbnail" && e.Thumbnail != "" {
+				img, err := readCellImage(i+1, col, e.Thumbnail)
+				if err != nil {
+					log.Printf("Error embedding thumbnail for node %s: %v", e.NodeID, err)
+				} else {
+					images = append(images, img)
+					thumbnailColumn = col
+					rowStyle = fmt.Sprintf("ro%d", len(images))
+					fmt.Fprintf(&rowStyles, `<style:style style:name="%s" style:family="table-row"><style:table-row-properties style:row-height="%.2fpt" style:use-optimal-row-height="false"/></style:style>`,
+						rowStyle, float64(img.Height)*0.75)
+					fmt.Fprintf(&cells, `<table:table-cell><draw:frame draw:name="Thumbnail%d" svg:width="%.2fpt" svg:height="%.2fpt" svg:x="0pt" svg:y="0pt"><draw:image xlink:href="%s" xlink:type="simple" xlink:show="embed" xlink:actuate="onLoad"/></draw:frame></table:table-cell>`,
+						len(images), float64(img.Width)*0.75, float64(img.Height)*0.75, odsPicturePath(len(images), img))
+					continue
+				}
+			}
+			writeODSCell(&cells, "", value)
+		}
+		if rowStyle != "" {
+			fmt.Fprintf(&rows, `<table:table-row table:style-name="%s">%s</table:table-row>`, rowStyle, cells.String())
+		} else {
+			fmt.Fprintf(&rows, `<table:table-row>%s</table:table-row>`, cells.String())
+		}
+	}
+
+	var content strings.Builder
+	content.WriteString(xml.Header)
+	content.WriteString(`<office:document-content ` + odsNamespaces + `><office:automatic-styles>`)
+	content.WriteString(`<style:style style:name="ceHeader" style:family="table-cell"><style:text-properties fo:font-weight="bold"/></style:style>`)
+	fmt.Fprintf(&content, `<style:style style:name="coThumbnail" style:family="table-column"><style:table-column-properties style:column-width="%.2fpt"/></style:style>`,
+		float64(thumbnailMaxWidth)*0.75)
+	content.WriteString(rowStyles.String())
+	content.WriteString(`</office:automatic-styles><office:body><office:spreadsheet>`)
+
+	content.WriteString(`<table:table table:name="Comments">`)
+	for col := range rep.Fields() {
+		if col == thumbnailColumn {
+			content.WriteString(`<table:table-column table:style-name="coThumbnail"/>`)
+		} else {
+			content.WriteString(`<table:table-column/>`)
+		}
+	}
+	content.WriteString(rows.String())
+	content.WriteString(`</table:table>`)
+
+	if len(rep.failures) > 0 {
+		writeODSErrorsTable(&content, rep.failures)
+	}
+	content.WriteString(`</office:spreadsheet></office:body></office:document-content>`)
+
+	return writeODSPackage([]byte(content.String()), images)
+}
+
+func writeODSRow(b *strings.Builder, cellStyle string, values []string) {
+	b.WriteString(`<table:table-row>`)
+	for _, value := range values {
+		writeODSCell(b, cellStyle, value)
+	}
+	b.WriteString(`</table:table-row>`)
+}
+
+// writeODSCell пишет строковую ячейку; каждая строка текста — отдельный
+// абзац, как это делает LibreOffice.
+func writeODSCell(b *strings.Builder, style, value string) {
+	b.WriteString(`<table:table-cell`)
+	if style != "" {
+		fmt.Fprintf(b, ` table:style-name="%s"`, style)
+	}
+	if value == "" {
+		b.WriteString(`/>`)
+		return
+	}
+	b.WriteString(` office:value-type="string">`)
+	for _, line := range strings.Split(strings.ReplaceAll(value, "\r\n", "\n"), "\n") {
+		b.WriteString(`<text:p>`)
+		writeODSText(b, line)
+		b.WriteString(`</text:p>`)
+	}
+	b.WriteString(`</table:table-cell>`)
+}
+
+// writeODSText пишет строку абзаца. ODF схлопывает пробелы, поэтому
+// повторные пробелы, а также пробелы в начале и в конце строки
+// записываются элементом text:s, табуляции — text:tab.
+func writeODSText(b *strings.Builder, line string) {
+	runes := []rune(line)
+	for i := 0; i < len(runes); {
+		j := i + 1
+		switch runes[i] {
+		case '\t':
+			b.WriteString(`<text:tab/>`)
+		case ' ':
+			for j < len(runes) && runes[j] == ' ' {
+				j++
+			}
+			n := j - i
+			// Одиночный пробел между словами остаётся текстом
+			if i > 0 && runes[i-1] != '\t' && j < len(runes) {
+				b.WriteByte(' ')
+				n--
+			}
+			switch {
+			case n == 1:
+				b.WriteString(`<text:s/>`)
+			case n > 1:
+				fmt.Fprintf(b, `<text:s text:c="%d"/>`, n)
+			}
+		default:
+			for j < len(runes) && runes[j] != ' ' && runes[j] != '\t' {
+				j++
+			}
+			xml.EscapeText(b, []byte(string(runes[i:j])))
+		}
+		i = j
+	}
+}
+
+func writeODSErrorsTable(b *strings.Builder, failures []failure) {
+	b.WriteString(`<table:table table:name="Errors"><table:table-column table:number-columns-repeated="5"/>`)
+	writeODSRow(b, "ceHeader", []string{"Source", "ID", "Status", "Reason", "Error"})
+	for _, f := range failures {
+		status := ""
+		if code := figma.StatusCode(f.Err); code != 0 {
+			status = strconv.Itoa(code)
+		}
+		writeODSRow(b, "", []string{f.Source, f.ID, status, figma.Reason(f.Err), f.Err.Error()})
+	}
+	b.WriteString(`</table:table>`)
+}
+
+type odsFile struct {
+	name string
+	data []byte
+}
+
+func odsPicturePath(n int, img cellImage) string {
+	return fmt.Sprintf("Pictures/thumbnail%d.%s", n, img.Ext)
+}
+
+// writeODSPackage собирает zip-контейнер: mimetype должен идти первым
+// и без сжатия.
+func writeODSPackage(content []byte, images []cellImage) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write([]byte(odsMIMEType)); err != nil {
+		return nil, err
+	}
+
+	var manifest strings.Builder
+	manifest.WriteString(xml.Header)
+	manifest.WriteString(`<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">`)
+	fmt.Fprintf(&manifest, `<manifest:file-entry manifest:full-path="/" manifest:version="1.2" manifest:media-type="%s"/>`, odsMIMEType)
+	manifest.WriteString(`<manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>`)
+	manifest.WriteString(`<manifest:file-entry manifest:full-path="styles.xml" manifest:media-type="text/xml"/>`)
+	for i, img := range images {
+		mediaType := "image/png"
+		if img.Ext == "jpg" {
+			mediaType = "image/jpeg"
+		}
+		fmt.Fprintf(&manifest, `<manifest:file-entry manifest:full-path="%s" manifest:media-type="%s"/>`, odsPicturePath(i+1, img), mediaType)
+	}
+	manifest.WriteString(`</manifest:manifest>`)
+
+	styles := xml.Header + `<office:document-styles ` + odsNamespaces + `/>`
+
+	files := []odsFile{
+		{"META-INF/manifest.xml", []byte(manifest.String())},
+		{"styles.xml", []byte(styles)},
+		{"content.xml", content},
+	}
+	for i, img := range images {
+		files = append(files, odsFile{odsPicturePath(i+1, img), img.Data})
+	}
+
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(f.data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
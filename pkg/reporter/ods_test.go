@@ -0,0 +1,84 @@
+package reporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriteODSCell(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", `<table:table-cell/>`},
+		{"a b", `<text:p>a b</text:p>`},
+		{"a  b", `<text:p>a <text:s/>b</text:p>`},
+		{"a    b", `<text:p>a <text:s text:c="3"/>b</text:p>`},
+		{"  a", `<text:p><text:s text:c="2"/>a</text:p>`},
+		{"a ", `<text:p>a<text:s/></text:p>`},
+		{" ", `<text:p><text:s/></text:p>`},
+		{"a\tb", `<text:p>a<text:tab/>b</text:p>`},
+		{"a\t b", `<text:p>a<text:tab/><text:s/>b</text:p>`},
+		{"x<y & \"z\"", `<text:p>x&lt;y &amp; &#34;z&#34;</text:p>`},
+		{"one\r\n  two", `<text:p>one</text:p><text:p><text:s text:c="2"/>two</text:p>`},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		writeODSCell(&b, "", tt.value)
+		if got := b.String(); !strings.Contains(got, tt.want) {
+			t.Errorf("writeODSCell(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestODSRenderer(t *testing.T) {
+	rep := testReport(t, odsRenderer{}, "comment_number", "message")
+	data, err := odsRenderer{}.Render(rep)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zr.File[0].Name != "mimetype" || zr.File[0].Method != zip.Store {
+		t.Errorf("first entry = %s (method %d), want stored mimetype", zr.File[0].Name, zr.File[0].Method)
+	}
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(content)
+	}
+
+	if files["mimetype"] != "application/vnd.oasis.opendocument.spreadsheet" {
+		t.Errorf("mimetype = %q", files["mimetype"])
+	}
+	content := files["content.xml"]
+	if err := xml.Unmarshal([]byte(content), new(struct{})); err != nil {
+		t.Fatalf("content.xml is not valid XML: %v", err)
+	}
+	for _, want := range []string{
+		`<text:p>Fix | the <text:s/>button</text:p><text:p>please</text:p>`,
+		`<text:p>Поправить отступ</text:p>`,
+		`table:name="Errors"`,
+		`<text:p>MISSING</text:p>`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("content.xml has no %s", want)
+		}
+	}
+}
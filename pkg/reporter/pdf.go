@@ -0,0 +1,214 @@
+package reporter
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Hikitak/figma-comment-reporter/internal/pdf"
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+)
+
+const (
+	pdfMargin     = 50.0
+	pdfBodySize   = 10.0
+	pdfSmallSize  = 9.0
+	pdfLineHeight = 1.35
+	// Отступ текста комментария и ответов от номера
+	pdfIndent = 18.0
+
+	pdfDateFormat = "2006-01-02 15:04"
+)
+
+// pdfRenderer собирает пакет для ревью: титульный лист со сводкой
+// и по разделу на файл с пронумерованными комментариями.
+type pdfRenderer struct{}
+
+func (pdfRenderer) Extension() string { return "pdf" }
+
+func (pdfRenderer) MIMEType() string { return "application/pdf" }
+
+// requiredFields: путь к узлу выводится у каждого комментария.
+func (pdfRenderer) requiredFields() []string {
+	return []string{"node_path"}
+}
+
+// pdfLayout ведёт курсор по странице и переносит вывод на новую
+// страницу, когда место заканчивается.
+type pdfLayout struct {
+	doc *pdf.Document
+	y   float64
+}
+
+func (l *pdfLayout) newPage() {
+	l.doc.AddPage()
+	l.y = pdfMargin
+}
+
+// ensure начинает новую страницу, если height не помещается на текущей.
+func (l *pdfLayout) ensure(height float64) {
+	if l.doc.PageCount() == 0 || l.y+height > pdf.PageHeight-pdfMargin {
+		l.newPage()
+	}
+}
+
+func (l *pdfLayout) width(indent float64) float64 {
+	return pdf.PageWidth - 2*pdfMargin - indent
+}
+
+// text выводит абзац с переносом строк.
+func (l *pdfLayout) text(font pdf.Font, size, gray, indent float64, s string) {
+	lineHeight := size * pdfLineHeight
+	for _, line := range pdf.Wrap(font, size, l.width(indent), s) {
+		l.ensure(lineHeight)
+		l.y += lineHeight
+		l.doc.Text(pdfMargin+indent, l.y-size*0.25, font, size, gray, line)
+	}
+}
+
+// link выводит подчёркнутую ссылку.
+func (l *pdfLayout) link(size, indent float64, title, url string) {
+	lineHeight := size * pdfLineHeight
+	l.ensure(lineHeight)
+	l.y += lineHeight
+	x := pdfMargin + indent
+	baseline := l.y - size*0.25
+	width := pdf.TextWidth(pdf.Regular, size, title)
+	l.doc.Text(x, baseline, pdf.Regular, size, 0.1, title)
+	l.doc.Line(x, baseline+1.5, x+width, baseline+1.5, 0.5, 0.1)
+	l.doc.Link(x, l.y-lineHeight, width, lineHeight, url)
+}
+
+func (l *pdfLayout) space(height float64) {
+	l.y += height
+}
+
+func (l *pdfLayout) rule() {
+	l.ensure(8)
+	l.y += 4
+	l.doc.Line(pdfMargin, l.y, pdf.PageWidth-pdfMargin, l.y, 0.5, 0.8)
+	l.y += 4
+}
+
+func (pdfRenderer) Render(rep *Report) ([]byte, error) {
+	doc := pdf.New()
+	doc.Title = "Figma Comments Report"
+	l := &pdfLayout{doc: doc}
+
+	dateFormat := pdfDateFormat
+	for _, field := range rep.Fields() {
+		if field.Name == "created_at" && field.Format != "" {
+			dateFormat = field.Format
+		}
+	}
+
+	files := groupEntries(rep.entries, fileGroupKey, fileTitle)
+	writePDFCover(l, rep, files, dateFormat)
+
+	for _, file := range files {
+		l.newPage()
+		l.text(pdf.Bold, 16, 0, 0, file.Title)
+		l.link(pdfSmallSize, 0, "Open file in Figma", file.Entries[0].fileURL())
+		l.space(6)
+
+		for i, e := range file.Entries {
+			writePDFEntry(l, rep, i+1, e, dateFormat)
+		}
+	}
+
+	return doc.Bytes()
+}
+
+func writePDFCover(l *pdfLayout, rep *Report, files []markdownGroup, dateFormat string) {
+	l.newPage()
+	l.space(120)
+	l.text(pdf.Bold, 26, 0, 0, "Figma Comments Report")
+	l.space(4)
+	l.text(pdf.Regular, 12, 0.4, 0, "Generated "+time.Now().Format(pdfDateFormat))
+	l.space(24)
+
+	open := 0
+	var first, last time.Time
+	for _, e := range rep.entries {
+		if e.Thread.Root.ResolvedAt == nil {
+			open++
+		}
+		created := e.Thread.Root.CreatedAt
+		if first.IsZero() || created.Before(first) {
+			first = created
+		}
+		if created.After(last) {
+			last = created
+		}
+	}
+
+	if len(rep.entries) > 0 {
+		l.text(pdf.Regular, 12, 0, 0, fmt.Sprintf("Period: %s – %s",
+			first.Format(dateFormat), last.Format(dateFormat)))
+	}
+	if since := rep.reporter.SinceVersion; since != "" {
+		l.text(pdf.Regular, 12, 0, 0, "Since version: "+since)
+	}
+	l.text(pdf.Regular, 12, 0, 0, fmt.Sprintf("Comments: %d (%d open, %d resolved)",
+		len(rep.entries), open, len(rep.entries)-open))
+	l.text(pdf.Regular, 12, 0, 0, fmt.Sprintf("Files: %d", len(files)))
+	l.space(16)
+
+	for _, file := range files {
+		fileOpen := 0
+		for _, e := range file.Entries {
+			if e.Thread.Root.ResolvedAt == nil {
+				fileOpen++
+			}
+		}
+		l.text(pdf.Regular, pdfBodySize, 0, 0, fmt.Sprintf("• %s — %d comments, %d open",
+			file.Title, len(file.Entries), fileOpen))
+	}
+
+	if len(rep.failures) > 0 {
+		l.space(16)
+		l.text(pdf.Bold, 12, 0, 0, "Not included")
+		for _, f := range rep.failures {
+			status := ""
+			if code := figma.StatusCode(f.Err); code != 0 {
+				status = " (HTTP " + strconv.Itoa(code) + ")"
+			}
+			l.text(pdf.Regular, pdfSmallSize, 0.3, 0, fmt.Sprintf("• %s %s%s: %s", f.Source, f.ID, status, f.Err.Error()))
+		}
+	}
+}
+
+func writePDFEntry(l *pdfLayout, rep *Report, number int, e entry, dateFormat string) {
+	root := e.Thread.Root
+	status := "open"
+	if root.ResolvedAt != nil {
+		status = "resolved"
+	}
+
+	// Заголовок не отрывается от первой строки текста
+	l.ensure(4 * pdfBodySize * pdfLineHeight)
+	header := fmt.Sprintf("%d. %s · %s · %s", number, root.User.Handle, root.CreatedAt.Format(dateFormat), status)
+	if root.OrderID != "" {
+		header += " · #" + root.OrderID
+	}
+	l.text(pdf.Bold, pdfBodySize, 0, 0, header)
+
+	location := e.NodeName
+	if len(e.Path) > 0 {
+		location = rep.reporter.getFieldValue(e, config.ReportField{Name: "node_path"})
+	}
+	l.text(pdf.Regular, pdfSmallSize, 0.4, pdfIndent, location)
+	l.space(2)
+	l.text(pdf.Regular, pdfBodySize, 0, pdfIndent, root.Message)
+
+	for _, reply := range e.Thread.Replies {
+		l.space(2)
+		l.text(pdf.Bold, pdfSmallSize, 0.3, 2*pdfIndent, fmt.Sprintf("%s · %s", reply.User.Handle, reply.CreatedAt.Format(dateFormat)))
+		l.text(pdf.Regular, pdfSmallSize, 0.3, 2*pdfIndent, reply.Message)
+	}
+
+	l.space(2)
+	l.link(pdfSmallSize, pdfIndent, "Open in Figma", rep.reporter.getFieldValue(e, config.ReportField{Name: "link"}))
+	l.rule()
+}
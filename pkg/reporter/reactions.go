@@ -0,0 +1,92 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+)
+
+// Поля отчёта, для которых нужны реакции
+var reactionFields = []string{"reactions", "reaction_count"}
+
+// Figma передаёт реакции в виде шорткодов, самые частые заменяем на эмодзи
+var emojiShortcodes = map[string]string{
+	":+1:":                     "👍",
+	":thumbsup:":               "👍",
+	":-1:":                     "👎",
+	":thumbsdown:":             "👎",
+	":heart:":                  "❤️",
+	":eyes:":                   "👀",
+	":fire:":                   "🔥",
+	":tada:":                   "🎉",
+	":joy:":                    "😂",
+	":smile:":                  "😄",
+	":thinking_face:":          "🤔",
+	":white_check_mark:":       "✅",
+	":heavy_check_mark:":       "✔️",
+	":x:":                      "❌",
+	":raised_hands:":           "🙌",
+	":clap:":                   "👏",
+	":pray:":                   "🙏",
+	":100:":                    "💯",
+	":rocket:":                 "🚀",
+	":warning:":                "⚠️",
+	":question:":               "❓",
+	":exclamation:":            "❗",
+	":heart_eyes:":             "😍",
+	":slightly_smiling_face:":  "🙂",
+	":disappointed:":           "😞",
+	":open_mouth:":             "😮",
+	":point_up:":               "☝️",
+	":ok_hand:":                "👌",
+	":star:":                   "⭐",
+	":bulb:":                   "💡",
+	":sparkles:":               "✨",
+	":see_no_evil:":            "🙈",
+	":face_with_rolling_eyes:": "🙄",
+	":heavy_plus_sign:":        "➕",
+	":heavy_minus_sign:":       "➖",
+}
+
+func emoji(shortcode string) string {
+	// Модификатор тона кожи (":+1::skin-tone-2:") отбрасываем
+	if i := strings.Index(shortcode, "::"); i >= 0 {
+		shortcode = shortcode[:i+1]
+	}
+	if e, ok := emojiShortcodes[shortcode]; ok {
+		return e
+	}
+	return shortcode
+}
+
+// formatReactions группирует реакции по эмодзи: "👍×4 👀×1".
+func formatReactions(reactions []figma.Reaction) string {
+	type group struct {
+		emoji string
+		count int
+	}
+	var groups []*group
+	index := make(map[string]*group)
+	for _, reaction := range reactions {
+		e := emoji(reaction.Emoji)
+		g, ok := index[e]
+		if !ok {
+			g = &group{emoji: e}
+			index[e] = g
+			groups = append(groups, g)
+		}
+		g.count++
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].count > groups[j].count
+	})
+
+	parts := make([]string, 0, len(groups))
+	for _, g := range groups {
+		parts = append(parts, fmt.Sprintf("%s×%d", g.emoji, g.count))
+	}
+	return strings.Join(parts, " ")
+}
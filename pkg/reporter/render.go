@@ -0,0 +1,119 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+)
+
+// DefaultFormat используется, если report.format не задан.
+const DefaultFormat = "xlsx"
+
+// Renderer записывает собранный отчёт в один из форматов вложения.
+type Renderer interface {
+	// Extension — расширение файла без точки
+	Extension() string
+	MIMEType() string
+	Render(report *Report) ([]byte, error)
+}
+
+// fieldRequirer — renderer, которому нужны данные полей, даже если
+// их нет среди колонок отчёта.
+type fieldRequirer interface {
+	requiredFields() []string
+}
+
+// NewRenderer возвращает renderer для значения report.format.
+func NewRenderer(format string) (Renderer, error) {
+	switch strings.ToLower(format) {
+	case "", "xlsx":
+		return xlsxRenderer{}, nil
+	case "csv":
+		return csvRenderer{}, nil
+	case "json":
+		return jsonRenderer{}, nil
+	case "ndjson":
+		return ndjsonRenderer{}, nil
+	case "html":
+		return htmlRenderer{}, nil
+	case "markdown", "md":
+		return markdownRenderer{}, nil
+	case "pdf":
+		return pdfRenderer{}, nil
+	case "ods":
+		return odsRenderer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+// Report — строки отчёта после фильтров и сортировки вместе с ошибками
+// источников, которые не удалось прочитать.
+type Report struct {
+	reporter *Reporter
+	entries  []entry
+	failures []failure
+}
+
+// Collect собирает отчёт один раз, чтобы его можно было записать
+// в несколько форматов. renderers — форматы, в которые он будет записан.
+func (r *Reporter) Collect(renderers ...Renderer) (*Report, error) {
+	r.required = nil
+	for _, renderer := range renderers {
+		if requirer, ok := renderer.(fieldRequirer); ok {
+			r.required = append(r.required, requirer.requiredFields()...)
+		}
+	}
+
+	entries, failures, err := r.gather()
+	if err != nil {
+		return nil, err
+	}
+
+	if r.usesField("thumbnail", "thumbnail_url") {
+		r.loadThumbnails(entries)
+	}
+
+	return &Report{reporter: r, entries: entries, failures: failures}, nil
+}
+
+// Generate собирает отчёт и записывает его renderer'ом.
+func (r *Reporter) Generate(renderer Renderer) ([]byte, error) {
+	report, err := r.Collect(renderer)
+	if err != nil {
+		return nil, err
+	}
+	return renderer.Render(report)
+}
+
+// Fields возвращает колонки отчёта в настроенном порядке.
+func (rep *Report) Fields() []config.ReportField {
+	return rep.reporter.Fields
+}
+
+// Header возвращает заголовки колонок.
+func (rep *Report) Header() []string {
+	header := make([]string, 0, len(rep.reporter.Fields))
+	for _, field := range rep.reporter.Fields {
+		header = append(header, field.Display)
+	}
+	return header
+}
+
+// Rows возвращает значения колонок для каждой строки отчёта.
+func (rep *Report) Rows() [][]string {
+	rows := make([][]string, 0, len(rep.entries))
+	for _, e := range rep.entries {
+		rows = append(rows, rep.values(e))
+	}
+	return rows
+}
+
+func (rep *Report) values(e entry) []string {
+	values := make([]string, 0, len(rep.reporter.Fields))
+	for _, field := range rep.reporter.Fields {
+		values = append(values, rep.reporter.getFieldValue(e, field))
+	}
+	return values
+}
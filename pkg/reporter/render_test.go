@@ -0,0 +1,185 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/tealeg/xlsx"
+)
+
+// testReport собирает отчёт по файлам KEY и MISSING: второй попадает
+// в ошибки источников.
+func testReport(t *testing.T, renderer Renderer, fields ...string) *Report {
+	t.Helper()
+	f := newFakeFigma(t)
+	r := f.reporter(fields...)
+	r.FileKeys = []string{"KEY", "MISSING"}
+	rep, err := r.Collect(renderer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rep
+}
+
+func TestNewRenderer(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantExt string
+		wantErr bool
+	}{
+		{"", "xlsx", false},
+		{"XLSX", "xlsx", false},
+		{"csv", "csv", false},
+		{"json", "json", false},
+		{"ndjson", "ndjson", false},
+		{"html", "html", false},
+		{"md", "md", false},
+		{"markdown", "md", false},
+		{"pdf", "pdf", false},
+		{"ods", "ods", false},
+		{"docx", "", true},
+	}
+	for _, tt := range tests {
+		renderer, err := NewRenderer(tt.format)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewRenderer(%q): err = %v, want error %v", tt.format, err, tt.wantErr)
+			continue
+		}
+		if err == nil && renderer.Extension() != tt.wantExt {
+			t.Errorf("NewRenderer(%q).Extension() = %q, want %q", tt.format, renderer.Extension(), tt.wantExt)
+		}
+	}
+}
+
+func TestCSVRenderer(t *testing.T) {
+	rep := testReport(t, csvRenderer{}, "comment_number", "message", "author")
+	data, err := csvRenderer{}.Render(rep)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v\n%s", err, data)
+	}
+	want := [][]string{
+		{"comment_number", "message", "author"},
+		{"1", "Fix | the  button\nplease", "ann"},
+		{"2", "Wrong color", "bob"},
+		{"3", "Поправить отступ", "кира"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d: %q", len(records), len(want), records)
+	}
+	for i := range want {
+		if strings.Join(records[i], "\x00") != strings.Join(want[i], "\x00") {
+			t.Errorf("record %d = %q, want %q", i, records[i], want[i])
+		}
+	}
+}
+
+func TestJSONRenderers(t *testing.T) {
+	tests := []struct {
+		name     string
+		renderer Renderer
+		// Разбирает вывод в строки отчёта и ошибки источников
+		parse func(t *testing.T, data []byte) ([]map[string]string, []map[string]string)
+		// Ожидаемое число ошибок источников
+		wantErrors int
+	}{
+		{
+			name:     "json",
+			renderer: jsonRenderer{},
+			parse: func(t *testing.T, data []byte) ([]map[string]string, []map[string]string) {
+				var doc struct {
+					Comments []map[string]string `json:"comments"`
+					Errors   []map[string]string `json:"errors"`
+				}
+				if err := json.Unmarshal(data, &doc); err != nil {
+					t.Fatalf("invalid JSON: %v\n%s", err, data)
+				}
+				return doc.Comments, doc.Errors
+			},
+			wantErrors: 1,
+		},
+		{
+			name:     "ndjson",
+			renderer: ndjsonRenderer{},
+			parse: func(t *testing.T, data []byte) ([]map[string]string, []map[string]string) {
+				var rows []map[string]string
+				for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+					var row map[string]string
+					if err := json.Unmarshal([]byte(line), &row); err != nil {
+						t.Fatalf("invalid line %q: %v", line, err)
+					}
+					rows = append(rows, row)
+				}
+				return rows, nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rep := testReport(t, tt.renderer, "comment_number", "message", "status")
+			data, err := tt.renderer.Render(rep)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows, failures := tt.parse(t, data)
+
+			if len(rows) != 3 {
+				t.Fatalf("got %d rows, want 3: %v", len(rows), rows)
+			}
+			if rows[0]["message"] != "Fix | the  button\nplease" || rows[1]["status"] != "resolved" {
+				t.Errorf("rows = %v", rows)
+			}
+			if len(failures) != tt.wantErrors {
+				t.Fatalf("got %d errors, want %d: %v", len(failures), tt.wantErrors, failures)
+			}
+			for _, f := range failures {
+				if f["id"] != "MISSING" || f["status"] != "404" || f["reason"] == "" {
+					t.Errorf("error = %v", f)
+				}
+			}
+		})
+	}
+}
+
+func TestXLSXRenderer(t *testing.T) {
+	rep := testReport(t, xlsxRenderer{}, "comment_number", "message", "node_name")
+	data, err := xlsxRenderer{}.Render(rep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := xlsx.OpenBinary(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		sheet string
+		row   int
+		col   int
+		want  string
+	}{
+		{"Comments", 0, 0, "comment_number"},
+		{"Comments", 1, 1, "Fix | the  button\nplease"},
+		{"Comments", 2, 2, "Card"},
+		{"Comments", 3, 0, "3"},
+		{"Errors", 1, 1, "MISSING"},
+	}
+	for _, tt := range tests {
+		sheet, ok := file.Sheet[tt.sheet]
+		if !ok {
+			t.Errorf("no sheet %q", tt.sheet)
+			continue
+		}
+		if got := sheet.Cell(tt.row, tt.col).String(); got != tt.want {
+			t.Errorf("%s[%d][%d] = %q, want %q", tt.sheet, tt.row, tt.col, got, tt.want)
+		}
+	}
+}
@@ -1,96 +1,287 @@
 package reporter
 
 import (
-	"bytes"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/tealeg/xlsx"
+	"github.com/Hikitak/figma-comment-reporter/pkg/cache"
 	"github.com/Hikitak/figma-comment-reporter/pkg/config"
 	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
 )
 
+const (
+	anchorNode    = "node"
+	anchorCanvas  = "canvas"
+	anchorDeleted = "deleted"
+)
+
 type Reporter struct {
-	Token    string
+	Client   *figma.Client
 	FileKeys []string
 	Fields   []config.ReportField
+	// Файлы с собственными токенами, псевдонимами и тегами
+	Files []config.FileConfig
+	// Включать комментарии на холсте и на удалённых узлах
+	IncludeUnanchored bool
+
+	// Поиск файлов в командах и проектах
+	TeamIDs    []string
+	ProjectIDs []string
+	Discovery  config.DiscoveryConfig
+
+	Sort    []config.SortRule
+	Filters []config.FilterRule
+	// Только комментарии, оставленные после версии с этим названием или ID
+	SinceVersion string
+	// Комментарии из веток файлов
+	Branches config.BranchConfig
+
+	Thumbnails config.ThumbnailConfig
+	Markdown   config.MarkdownConfig
+
+	// Кэш сведений о файлах на диске, nil — без кэша
+	Cache *cache.Cache
+	// Скачивать миниатюры заново, не глядя на кэш на диске
+	RefreshThumbnails bool
+
+	// Поля, нужные форматам вывода текущего отчёта
+	required []string
 }
 
-func New(token string, fileKeys []string, fields []config.ReportField) *Reporter {
+type entry struct {
+	File     fileTarget
+	FileName string
+	Thread   figma.Thread
+	Anchor   string
+	NodeID   string
+	NodeName string
+	// Путь от страницы до узла
+	Path []string
+	// Именованная версия файла на момент создания комментария
+	Version              *figma.Version
+	CommentsSinceVersion int
+	// Ссылка на отрендеренный узел и его копия в кэше на диске
+	ThumbnailURL string
+	Thumbnail    string
+	// Метаданные файла, если они нужны полям отчёта
+	FileMeta *figma.FileMeta
+}
+
+func New(client *figma.Client, fileKeys []string, fields []config.ReportField) *Reporter {
 	return &Reporter{
-		Token:    token,
+		Client:   client,
 		FileKeys: fileKeys,
 		Fields:   fields,
 	}
 }
 
-func (r *Reporter) Generate() ([]byte, error) {
-	file := xlsx.NewFile()
-	sheet, err := file.AddSheet("Comments")
-	if err != nil {
-		return nil, err
-	}
+// gather собирает комментарии всех файлов, применяя фильтры и сортировку.
+func (r *Reporter) gather() ([]entry, []failure, error) {
+	r.Client.ResetRetryBudget()
 
-	headerRow := sheet.AddRow()
-	for _, field := range r.Fields {
-		cell := headerRow.AddCell()
-		cell.Value = field.Display
+	if err := validateFilters(r.Filters); err != nil {
+		return nil, nil, err
 	}
 
-	for _, fileKey := range r.FileKeys {
-		comments, err := figma.GetComments(fileKey, r.Token)
+	targets, failures := r.resolveFiles()
+
+	var entries []entry
+	for _, target := range targets {
+		fileEntries, err := r.collect(target)
 		if err != nil {
-			log.Printf("Error getting comments for file %s: %v", fileKey, err)
+			log.Printf("Error collecting comments for file %s: %v", target.Key, err)
+			failures = append(failures, failure{Source: "file", ID: target.Key, Err: err})
 			continue
 		}
+		entries = append(entries, fileEntries...)
+	}
 
-		parentComments, nodeIDs := figma.FilterParentComments(comments)
-		if len(nodeIDs) == 0 {
-			continue
-		}
+	entries = r.filterEntries(entries)
+	r.sortEntries(entries)
+	return entries, failures, nil
+}
 
-		nodesResponse, err := figma.GetFileNodes(fileKey, r.Token, nodeIDs)
+func (r *Reporter) collect(target fileTarget) ([]entry, error) {
+	fileKey := target.Key
+	client := target.Client
+	comments, err := client.GetComments(fileKey)
+	if err != nil {
+		return nil, fmt.Errorf("getting comments: %w", err)
+	}
+
+	threads := figma.BuildThreads(comments)
+
+	var versions versionIndex
+	if r.SinceVersion != "" || r.usesField("version_label", "version_id", "comments_since_version") {
+		history, err := client.GetVersions(fileKey)
 		if err != nil {
-			log.Printf("Error getting nodes for file %s: %v", fileKey, err)
-			continue
+			return nil, fmt.Errorf("getting versions: %w", err)
 		}
-
-		for _, comment := range parentComments {
-			nodeID := comment.ClientMeta.NodeID
-			if node, exists := nodesResponse.Nodes[nodeID]; exists {
-				row := sheet.AddRow()
-				for _, field := range r.Fields {
-					value := r.getFieldValue(comment, node, fileKey, nodesResponse.Name, field)
-					cell := row.AddCell()
-					cell.Value = value
-				}
-			}
+		versions = newVersionIndex(history)
+	}
+	if r.SinceVersion != "" {
+		threads, err = filterSinceVersion(threads, versions, r.SinceVersion)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	var buf bytes.Buffer
-	if err := file.Write(&buf); err != nil {
+	fileThreads := threads
+	nodeThreads, nodeIDs := figma.FilterNodeThreads(threads)
+	if !r.IncludeUnanchored {
+		threads = nodeThreads
+	}
+	if len(threads) == 0 {
+		return nil, nil
+	}
+
+	data, err := r.loadFileData(client, fileKey, nodeIDs)
+	if err != nil {
 		return nil, err
 	}
-	return buf.Bytes(), nil
+
+	// Строки веток подписываются именем основного файла
+	fileName := data.Name
+	if target.MainName != "" {
+		fileName = target.MainName
+	}
+
+	var entries []entry
+	for _, thread := range threads {
+		e := entry{
+			File:     target,
+			FileName: fileName,
+			Thread:   thread,
+			NodeID:   thread.Root.ClientMeta.NodeID,
+			FileMeta: data.Meta,
+		}
+		if v := versions.at(thread.Root.CreatedAt); v != nil {
+			e.Version = v
+			e.CommentsSinceVersion = countSince(fileThreads, v.CreatedAt)
+		}
+
+		node := data.Nodes[e.NodeID]
+		switch {
+		case e.NodeID == "":
+			e.Anchor = anchorCanvas
+			e.NodeID = "(canvas)"
+			e.NodeName = "(canvas)"
+		case node == nil:
+			// Узел удалён или не вернулся в ответе, ID оставляем исходный
+			e.Anchor = anchorDeleted
+			e.NodeName = "(deleted node)"
+		default:
+			e.Anchor = anchorNode
+			e.NodeID = node.Document.ID
+			e.NodeName = node.Document.Name
+			e.Path = data.Paths[thread.Root.ClientMeta.NodeID]
+		}
+
+		if e.Anchor != anchorNode && !r.IncludeUnanchored {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	if r.usesField(reactionFields...) {
+		r.loadReactions(client, fileKey, entries)
+	}
+
+	return entries, nil
 }
 
-func (r *Reporter) getFieldValue(comment figma.Comment, node figma.Node, fileID, fileName string, field config.ReportField) string {
+func (r *Reporter) getFieldValue(e entry, field config.ReportField) string {
+	thread := e.Thread
+	comment := thread.Root
 	switch field.Name {
 	case "file_name":
-		return fileName
+		return e.FileName
 	case "file_id":
-		return fileID
+		return e.File.FileID()
+	case "branch_name":
+		return e.File.BranchName
+	case "branch_key":
+		return e.File.BranchKey()
+	case "file_alias":
+		return e.File.Alias
+	case "file_tags":
+		return strings.Join(e.File.Tags, ", ")
+	case "file_last_modified":
+		if e.FileMeta != nil {
+			return formatTime(e.FileMeta.LastTouchedAt, field.Format)
+		}
+		return ""
+	case "editor_type":
+		if e.FileMeta != nil {
+			return e.FileMeta.EditorType
+		}
+		return ""
+	case "file_thumbnail_url":
+		if e.FileMeta != nil {
+			return e.FileMeta.ThumbnailURL
+		}
+		return ""
+	case "file_role":
+		if e.FileMeta != nil {
+			return e.FileMeta.Role
+		}
+		return ""
+	case "file_link":
+		if e.FileMeta != nil && e.FileMeta.URL != "" {
+			return e.FileMeta.URL
+		}
+		return e.fileURL()
+	case "project_id":
+		return e.File.ProjectID
+	case "project_name":
+		return e.File.ProjectName
 	case "node_name":
-		return node.Document.Name
+		return e.NodeName
 	case "node_id":
-		return node.Document.ID
+		return e.NodeID
+	case "anchor_type":
+		return e.Anchor
+	case "page_name":
+		if len(e.Path) > 0 {
+			return e.Path[0]
+		}
+		return ""
+	case "top_frame_name":
+		if len(e.Path) > 1 {
+			return e.Path[1]
+		}
+		return ""
+	case "node_path":
+		return strings.Join(e.Path, " / ")
+	case "comment_number":
+		return comment.OrderID
 	case "message":
 		return comment.Message
 	case "author":
 		return comment.User.Handle
+	case "author_id":
+		return comment.User.ID
+	case "author_avatar":
+		return comment.User.ImgURL
+	case "pos_x":
+		if pos, ok := comment.ClientMeta.Position(); ok {
+			return formatFloat(pos.X)
+		}
+		return ""
+	case "pos_y":
+		if pos, ok := comment.ClientMeta.Position(); ok {
+			return formatFloat(pos.Y)
+		}
+		return ""
+	case "region":
+		if comment.ClientMeta.HasRegion() {
+			return formatFloat(comment.ClientMeta.RegionWidth) + "x" + formatFloat(comment.ClientMeta.RegionHeight)
+		}
+		return ""
 	case "created_at":
 		return formatTime(comment.CreatedAt, field.Format)
 	case "status":
@@ -103,17 +294,142 @@ func (r *Reporter) getFieldValue(comment figma.Comment, node figma.Node, fileID,
 			return formatTime(*comment.ResolvedAt, field.Format)
 		}
 		return ""
+	case "reply_count":
+		return strconv.Itoa(thread.ReplyCount())
+	case "last_reply_at":
+		if reply := thread.LastReply(); reply != nil {
+			return formatTime(reply.CreatedAt, field.Format)
+		}
+		return ""
+	case "last_reply_author":
+		if reply := thread.LastReply(); reply != nil {
+			return reply.User.Handle
+		}
+		return ""
+	case "last_activity_at":
+		return formatTime(thread.LastActivity(), field.Format)
+	case "thread":
+		return formatThread(thread, field.Format)
+	case "reactions":
+		return formatReactions(comment.Reactions)
+	case "reaction_count":
+		return strconv.Itoa(len(comment.Reactions))
+	case "thumbnail":
+		// Изображение вставляется в ячейку при записи XLSX
+		return ""
+	case "thumbnail_url":
+		return e.ThumbnailURL
+	case "version_label":
+		if e.Version != nil {
+			return e.Version.Label
+		}
+		return ""
+	case "version_id":
+		if e.Version != nil {
+			return e.Version.ID
+		}
+		return ""
+	case "comments_since_version":
+		if e.Version != nil {
+			return strconv.Itoa(e.CommentsSinceVersion)
+		}
+		return ""
 	case "link":
-		return fmt.Sprintf("https://www.figma.com/design/%s?node-id=%s#%s",
-			fileID, strings.Replace(comment.ClientMeta.NodeID, ":", "-", 1), comment.ID)
+		fileURL := e.fileURL()
+		if comment.ClientMeta.NodeID == "" {
+			return fmt.Sprintf("%s#%s", fileURL, comment.ID)
+		}
+		return fmt.Sprintf("%s?node-id=%s#%s",
+			fileURL, strings.Replace(comment.ClientMeta.NodeID, ":", "-", 1), comment.ID)
 	default:
 		return ""
 	}
 }
 
+// fileURL возвращает ссылку на файл, а для веток — на ветку.
+func (e entry) fileURL() string {
+	fileURL := "https://www.figma.com/design/" + e.File.FileID()
+	if branchKey := e.File.BranchKey(); branchKey != "" {
+		fileURL += "/branch/" + branchKey
+	}
+	return fileURL
+}
+
+// loadReactions запрашивает реакции для строк, у которых их нет в ответе
+// со списком комментариев. Строки, которые всё равно отсеют фильтры по
+// другим полям, пропускаются; одновременно идёт не больше NodeConcurrency
+// запросов.
+func (r *Reporter) loadReactions(client *figma.Client, fileKey string, entries []entry) {
+	var pending []*figma.Comment
+	for i := range entries {
+		root := &entries[i].Thread.Root
+		if root.Reactions != nil || !r.matchFilters(entries[i], reactionFields...) {
+			continue
+		}
+		pending = append(pending, root)
+	}
+
+	sem := make(chan struct{}, max(client.NodeConcurrency, 1))
+	var wg sync.WaitGroup
+	for _, root := range pending {
+		wg.Add(1)
+		go func(root *figma.Comment) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			reactions, err := client.GetReactions(fileKey, root.ID)
+			if err != nil {
+				log.Printf("Error getting reactions for comment %s: %v", root.ID, err)
+				return
+			}
+			root.Reactions = reactions
+		}(root)
+	}
+	wg.Wait()
+}
+
+// usesField проверяет, нужно ли хотя бы одно из полей для колонок,
+// сортировки или фильтров отчёта.
+func (r *Reporter) usesField(names ...string) bool {
+	used := make([]string, 0, len(r.Fields)+len(r.Sort)+len(r.Filters))
+	for _, field := range r.Fields {
+		used = append(used, field.Name)
+	}
+	for _, rule := range r.Sort {
+		used = append(used, rule.Field)
+	}
+	for _, filter := range r.Filters {
+		used = append(used, filter.Field)
+	}
+	used = append(used, r.required...)
+
+	for _, u := range used {
+		for _, name := range names {
+			if u == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func formatThread(thread figma.Thread, format string) string {
+	lines := make([]string, 0, thread.ReplyCount()+1)
+	for _, comment := range thread.Comments() {
+		lines = append(lines, fmt.Sprintf("%s (%s): %s",
+			comment.User.Handle, formatTime(comment.CreatedAt, format), comment.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
 func formatTime(t time.Time, format string) string {
 	if format == "" {
 		return t.Format(time.RFC3339)
 	}
 	return t.Format(format)
-}
\ No newline at end of file
+}
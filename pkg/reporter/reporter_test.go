@@ -0,0 +1,263 @@
+package reporter
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+)
+
+const testComments = `{"comments":[
+{"id":"c1","order_id":"1","created_at":"2024-01-01T10:00:00Z","user":{"id":"u1","handle":"ann"},"message":"Fix | the  button\nplease","client_meta":{"node_id":"1:3"}},
+{"id":"c2","parent_id":"c1","created_at":"2024-01-02T10:00:00Z","user":{"handle":"bob"},"message":"Done","client_meta":{}},
+{"id":"c3","order_id":"2","created_at":"2024-01-03T10:00:00Z","resolved_at":"2024-01-06T10:00:00Z","user":{"handle":"bob"},"message":"Wrong color","client_meta":{"node_id":"1:4"}},
+{"id":"c4","order_id":"3","created_at":"2024-01-04T10:00:00Z","user":{"handle":"кира"},"message":"Поправить отступ","client_meta":{"node_id":"1:4"},"reactions":[{"emoji":":eyes:"}]},
+{"id":"c5","order_id":"4","created_at":"2024-01-05T10:00:00Z","user":{"handle":"ann"},"message":"Canvas note","client_meta":{"x":1,"y":2}}
+]}`
+
+const testTree = `{"name":"Checkout","document":{"id":"0:0","type":"DOCUMENT","children":[
+{"id":"0:1","name":"Page 1","type":"CANVAS","children":[
+{"id":"1:1","name":"Payment modal","type":"FRAME","children":[
+{"id":"1:3","name":"Button","type":"RECTANGLE"},
+{"id":"1:4","name":"Card","type":"FRAME"}]}]}]}}`
+
+const testVersions = `{"versions":[
+{"id":"v2","created_at":"2024-01-03T12:00:00Z","label":"Review 2"},
+{"id":"v1","created_at":"2024-01-02T12:00:00Z","label":"Review 1"},
+{"id":"v0","created_at":"2024-01-01T00:00:00Z","label":""}
+]}`
+
+// fakeFigma отвечает на запросы отчёта тестовыми данными и считает вызовы
+// по видам запросов.
+type fakeFigma struct {
+	*httptest.Server
+	// Ответ /meta; пустая строка — ошибка 500
+	meta string
+
+	mu        sync.Mutex
+	calls     map[string]int
+	requests  []fakeRequest
+	active    int
+	maxActive int
+}
+
+type fakeRequest struct {
+	kind  string
+	path  string
+	query url.Values
+	token string
+}
+
+func newFakeFigma(t *testing.T) *fakeFigma {
+	f := &fakeFigma{
+		meta:  `{"file":{"name":"Checkout","version":"42","last_touched_at":"2024-02-01T00:00:00Z","editorType":"figma","role":"editor"}}`,
+		calls: make(map[string]int),
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeFigma) count(kind string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[kind]
+}
+
+// queries возвращает параметры запросов вида kind в порядке получения.
+func (f *fakeFigma) queries(kind string) []url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var queries []url.Values
+	for _, req := range f.requests {
+		if req.kind == kind {
+			queries = append(queries, req.query)
+		}
+	}
+	return queries
+}
+
+// tokens возвращает токены, с которыми запрашивался файл fileKey.
+func (f *fakeFigma) tokens(fileKey string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var tokens []string
+	for _, req := range f.requests {
+		if slices.Contains(strings.Split(req.path, "/"), fileKey) && !slices.Contains(tokens, req.token) {
+			tokens = append(tokens, req.token)
+		}
+	}
+	return tokens
+}
+
+func (f *fakeFigma) serve(w http.ResponseWriter, r *http.Request) {
+	p := r.URL.Path
+	kind := "file"
+	switch {
+	case strings.HasPrefix(p, "/v1/images/"):
+		kind = "images"
+	case strings.HasPrefix(p, "/img/"):
+		kind = "download"
+	case strings.HasSuffix(p, "/reactions"):
+		kind = "reactions"
+	case strings.HasSuffix(p, "/comments"):
+		kind = "comments"
+	case strings.HasSuffix(p, "/meta"):
+		kind = "meta"
+	case strings.HasSuffix(p, "/nodes"):
+		kind = "nodes"
+	case strings.HasSuffix(p, "/versions"):
+		kind = "versions"
+	case r.URL.Query().Get("branch_data") == "true":
+		kind = "branches"
+	case strings.HasPrefix(p, "/v1/teams/"):
+		kind = "projects"
+	case strings.HasPrefix(p, "/v1/projects/"):
+		kind = "project_files"
+	}
+
+	f.mu.Lock()
+	f.calls[kind]++
+	f.requests = append(f.requests, fakeRequest{kind: kind, path: p, query: r.URL.Query(), token: r.Header.Get("X-FIGMA-TOKEN")})
+	f.active++
+	f.maxActive = max(f.maxActive, f.active)
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.active--
+		f.mu.Unlock()
+	}()
+
+	// Файл MISSING не существует
+	if strings.Contains(p, "/MISSING") {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"status":404,"err":"Not found"}`)
+		return
+	}
+
+	switch kind {
+	case "reactions":
+		time.Sleep(10 * time.Millisecond)
+		if strings.Contains(p, "/c1/") {
+			fmt.Fprint(w, `{"reactions":[{"emoji":":+1:"},{"emoji":":+1:"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"reactions":[]}`)
+	case "comments":
+		fmt.Fprint(w, testComments)
+	case "meta":
+		if f.meta == "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, f.meta)
+	case "nodes":
+		names := map[string]string{"1:3": "Button", "1:4": "Card"}
+		var nodes []string
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			if name, ok := names[id]; ok {
+				nodes = append(nodes, fmt.Sprintf(`"%s":{"document":{"id":"%s","name":"%s"}}`, id, id, name))
+			}
+		}
+		fmt.Fprintf(w, `{"name":"Checkout","nodes":{%s}}`, strings.Join(nodes, ","))
+	case "versions":
+		fmt.Fprint(w, testVersions)
+	case "projects":
+		fmt.Fprint(w, `{"projects":[{"id":"P1","name":"Design"},{"id":"P2","name":"Marketing"}]}`)
+	case "project_files":
+		// Даты относительно текущего момента для modified_within_days
+		day := func(n int) string {
+			return time.Now().AddDate(0, 0, -n).UTC().Format(time.RFC3339)
+		}
+		files := map[string]string{
+			"P1": fmt.Sprintf(`{"key":"KEY","name":"Checkout","last_modified":"%s"},{"key":"D1","name":"Onboarding","last_modified":"%s"},{"key":"D2","name":"Archive / Old flows","last_modified":"%s"}`, day(0), day(2), day(1)),
+			"P2": fmt.Sprintf(`{"key":"D3","name":"Landing","last_modified":"%s"},{"key":"D4","name":"Campaign 2019","last_modified":"%s"}`, day(1), day(400)),
+		}
+		id := strings.TrimSuffix(strings.TrimPrefix(p, "/v1/projects/"), "/files")
+		fmt.Fprintf(w, `{"name":"Project %s","files":[%s]}`, id, files[id])
+	case "branches":
+		// Ветки есть только у KEY: обычная и архивная
+		branches := ""
+		if p == "/v1/files/KEY" {
+			branches = `{"key":"BR1","name":"Redesign"},{"key":"BR2","name":"Old idea","archived":true}`
+		}
+		fmt.Fprintf(w, `{"name":"Checkout","branches":[%s]}`, branches)
+	case "images":
+		var images []string
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			images = append(images, fmt.Sprintf(`"%s":"%s/img/%s.png"`, id, f.URL, id))
+		}
+		fmt.Fprintf(w, `{"err":null,"images":{%s}}`, strings.Join(images, ","))
+	case "download":
+		w.Header().Set("Content-Type", "image/png")
+		png.Encode(w, image.NewRGBA(image.Rect(0, 0, 40, 20)))
+	default:
+		fmt.Fprint(w, testTree)
+	}
+}
+
+// reporter возвращает отчёт по файлу KEY с полями fields.
+func (f *fakeFigma) reporter(fields ...string) *Reporter {
+	client := figma.NewClient("token")
+	client.BaseURL = f.URL
+	client.Retry.MaxRetries = 0
+
+	var reportFields []config.ReportField
+	for _, name := range fields {
+		reportFields = append(reportFields, config.ReportField{Name: name, Display: name})
+	}
+	return New(client, []string{"KEY"}, reportFields)
+}
+
+// column возвращает значения поля name во всех строках отчёта.
+func column(r *Reporter, entries []entry, name string) []string {
+	values := make([]string, len(entries))
+	for i, e := range entries {
+		values[i] = r.getFieldValue(e, config.ReportField{Name: name})
+	}
+	return values
+}
+
+func TestGather(t *testing.T) {
+	tests := []struct {
+		name       string
+		unanchored bool
+		field      string
+		want       []string
+	}{
+		{"node names", false, "node_name", []string{"Button", "Card", "Card"}},
+		{"with canvas", true, "node_name", []string{"Button", "Card", "Card", "(canvas)"}},
+		{"replies", false, "reply_count", []string{"1", "0", "0"}},
+		{"status", false, "status", []string{"open", "resolved", "open"}},
+		{"path", false, "node_path", []string{"Page 1 / Payment modal / Button", "Page 1 / Payment modal / Card", "Page 1 / Payment modal / Card"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFigma(t)
+			r := f.reporter(tt.field)
+			r.IncludeUnanchored = tt.unanchored
+
+			entries, failures, err := r.gather()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(failures) > 0 {
+				t.Fatalf("unexpected failures: %v", failures)
+			}
+			if got := column(r, entries, tt.field); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("%s = %q, want %q", tt.field, got, tt.want)
+			}
+		})
+	}
+}
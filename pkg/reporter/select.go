@@ -0,0 +1,94 @@
+package reporter
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+)
+
+// Selection — тред, прошедший фильтры отчёта, вместе с клиентом,
+// которым можно изменить его в Figma.
+type Selection struct {
+	// Ключ, по которому доступен комментарий (для веток — ключ ветки)
+	FileKey  string
+	FileName string
+	NodeName string
+	Thread   figma.Thread
+	Client   *figma.Client
+}
+
+// Select возвращает треды, отобранные Filters, в порядке Sort. Ошибки
+// отдельных файлов возвращаются вместе с тем, что удалось собрать.
+func (r *Reporter) Select() ([]Selection, error) {
+	entries, failures, err := r.gather()
+	if err != nil {
+		return nil, err
+	}
+
+	selections := make([]Selection, 0, len(entries))
+	for _, e := range entries {
+		selections = append(selections, Selection{
+			FileKey:  e.File.Key,
+			FileName: e.FileName,
+			NodeName: e.NodeName,
+			Thread:   e.Thread,
+			Client:   e.File.Client,
+		})
+	}
+
+	if len(failures) > 0 {
+		return selections, fmt.Errorf("%d source(s) could not be read, first: %s %s: %w",
+			len(failures), failures[0].Source, failures[0].ID, failures[0].Err)
+	}
+	return selections, nil
+}
+
+// SelectComment находит комментарий fileKey по ID. Ответ возвращается
+// отдельным тредом без ответов; узел определяется по его треду так же,
+// как в отчёте.
+func (r *Reporter) SelectComment(fileKey, commentID string) (Selection, error) {
+	client := r.Client
+	for _, file := range r.Files {
+		if file.Key == fileKey && file.Token != "" {
+			client = r.Client.WithToken(file.Token)
+		}
+	}
+
+	comments, err := client.GetComments(fileKey)
+	if err != nil {
+		return Selection{}, fmt.Errorf("getting comments for file %s: %w", fileKey, err)
+	}
+
+	for _, thread := range figma.BuildThreads(comments) {
+		var selected *figma.Thread
+		if thread.Root.ID == commentID {
+			selected = &thread
+		}
+		for _, reply := range thread.Replies {
+			if reply.ID == commentID {
+				selected = &figma.Thread{Root: reply}
+			}
+		}
+		if selected == nil {
+			continue
+		}
+
+		selection := Selection{FileKey: fileKey, NodeName: "(canvas)", Thread: *selected, Client: client}
+		if nodeID := thread.Root.ClientMeta.NodeID; nodeID != "" {
+			selection.NodeName = nodeID
+			data, err := r.loadFileData(client, fileKey, []string{nodeID})
+			if err != nil {
+				log.Printf("Error getting node %s of file %s: %v", nodeID, fileKey, err)
+			} else {
+				selection.FileName = data.Name
+				selection.NodeName = "(deleted node)"
+				if node := data.Nodes[nodeID]; node != nil {
+					selection.NodeName = node.Document.Name
+				}
+			}
+		}
+		return selection, nil
+	}
+	return Selection{}, fmt.Errorf("comment %s not found in file %s", commentID, fileKey)
+}
@@ -0,0 +1,58 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/config"
+)
+
+func TestSelectComment(t *testing.T) {
+	tests := []struct {
+		id       string
+		wantRoot string
+		wantNode string
+		wantErr  bool
+	}{
+		{id: "c1", wantRoot: "c1", wantNode: "Button"},
+		// Ответ выбирается без своих ответов, узел — от треда
+		{id: "c2", wantRoot: "c2", wantNode: "Button"},
+		{id: "c4", wantRoot: "c4", wantNode: "Card"},
+		{id: "c5", wantRoot: "c5", wantNode: "(canvas)"},
+		{id: "c9", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			f := newFakeFigma(t)
+			r := f.reporter("node_name")
+
+			selection, err := r.SelectComment("KEY", tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if selection.Thread.Root.ID != tt.wantRoot || selection.NodeName != tt.wantNode {
+				t.Errorf("selected %s on %q, want %s on %q", selection.Thread.Root.ID, selection.NodeName, tt.wantRoot, tt.wantNode)
+			}
+			if selection.FileKey != "KEY" || selection.Client == nil {
+				t.Errorf("selection = %+v", selection)
+			}
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	f := newFakeFigma(t)
+	r := f.reporter("node_name")
+	r.Filters = []config.FilterRule{{Field: "status", Value: "open"}}
+
+	selections, err := r.Select()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selections) != 2 || selections[0].NodeName != "Button" || selections[1].Thread.Root.ID != "c4" {
+		t.Errorf("selections = %+v", selections)
+	}
+}
@@ -0,0 +1,136 @@
+package reporter
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+)
+
+const (
+	defaultThumbnailScale    = 1
+	defaultThumbnailFormat   = "png"
+	defaultThumbnailCacheTTL = 24 * time.Hour
+)
+
+func (r *Reporter) thumbnailOptions() figma.ImageOptions {
+	opts := figma.ImageOptions{
+		Scale:  r.Thumbnails.Scale,
+		Format: r.Thumbnails.Format,
+	}
+	if opts.Scale <= 0 {
+		opts.Scale = defaultThumbnailScale
+	}
+	if opts.Format == "" {
+		opts.Format = defaultThumbnailFormat
+	}
+	return opts
+}
+
+func (r *Reporter) thumbnailPath(fileKey, nodeID string) string {
+	dir := r.Thumbnails.CacheDir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "figma-thumbnails")
+	}
+	opts := r.thumbnailOptions()
+	name := fmt.Sprintf("%s@%gx.%s", strings.NewReplacer(":", "-", ";", "_").Replace(nodeID), opts.Scale, opts.Format)
+	return filepath.Join(dir, fileKey, name)
+}
+
+func (r *Reporter) thumbnailFresh(path string) bool {
+	if r.RefreshThumbnails {
+		return false
+	}
+	ttl := r.Thumbnails.CacheTTL
+	if ttl <= 0 {
+		ttl = defaultThumbnailCacheTTL
+	}
+	info, err := os.Stat(path)
+	return err == nil && time.Since(info.ModTime()) < ttl
+}
+
+// loadThumbnails заполняет ссылки на отрендеренные узлы и, если нужна
+// колонка thumbnail, скачивает изображения в кэш на диске.
+func (r *Reporter) loadThumbnails(entries []entry) {
+	byFile := make(map[string][]int)
+	var fileKeys []string
+	for i, e := range entries {
+		if e.Anchor != anchorNode {
+			continue
+		}
+		if _, ok := byFile[e.File.Key]; !ok {
+			fileKeys = append(fileKeys, e.File.Key)
+		}
+		byFile[e.File.Key] = append(byFile[e.File.Key], i)
+	}
+
+	for _, fileKey := range fileKeys {
+		r.loadFileThumbnails(fileKey, entries, byFile[fileKey])
+	}
+}
+
+func (r *Reporter) loadFileThumbnails(fileKey string, entries []entry, indices []int) {
+	withURL := r.usesField("thumbnail_url")
+	withImage := r.usesField("thumbnail")
+	client := entries[indices[0]].File.Client
+
+	ids := make(map[string]bool)
+	for _, i := range indices {
+		e := entries[i]
+		if withURL || !r.thumbnailFresh(r.thumbnailPath(fileKey, e.NodeID)) {
+			ids[e.NodeID] = true
+		}
+	}
+
+	urls := map[string]string{}
+	if len(ids) > 0 {
+		nodeIDs := make([]string, 0, len(ids))
+		for id := range ids {
+			nodeIDs = append(nodeIDs, id)
+		}
+
+		var err error
+		urls, err = client.GetImages(fileKey, nodeIDs, r.thumbnailOptions())
+		if err != nil {
+			log.Printf("Error rendering thumbnails for file %s: %v", fileKey, err)
+			urls = map[string]string{}
+		}
+	}
+
+	downloaded := make(map[string]bool)
+	for _, i := range indices {
+		e := &entries[i]
+		e.ThumbnailURL = urls[e.NodeID]
+		if !withImage {
+			continue
+		}
+
+		path := r.thumbnailPath(fileKey, e.NodeID)
+		if !downloaded[path] && !r.thumbnailFresh(path) {
+			if e.ThumbnailURL == "" {
+				continue
+			}
+			if err := downloadThumbnail(client, e.ThumbnailURL, path); err != nil {
+				log.Printf("Error downloading thumbnail for node %s: %v", e.NodeID, err)
+				continue
+			}
+		}
+		downloaded[path] = true
+		e.Thumbnail = path
+	}
+}
+
+func downloadThumbnail(client *figma.Client, imageURL, path string) error {
+	data, err := client.Download(imageURL)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
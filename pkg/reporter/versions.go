@@ -0,0 +1,76 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+)
+
+// versionIndex — именованные версии файла от старых к новым.
+// Автосохранения без названия не учитываются.
+type versionIndex []figma.Version
+
+func newVersionIndex(versions []figma.Version) versionIndex {
+	var named versionIndex
+	for _, v := range versions {
+		if v.Label != "" {
+			named = append(named, v)
+		}
+	}
+	sort.SliceStable(named, func(i, j int) bool {
+		return named[i].CreatedAt.Before(named[j].CreatedAt)
+	})
+	return named
+}
+
+// at возвращает версию, актуальную на момент t.
+func (idx versionIndex) at(t time.Time) *figma.Version {
+	i := sort.Search(len(idx), func(i int) bool {
+		return idx[i].CreatedAt.After(t)
+	})
+	if i == 0 {
+		return nil
+	}
+	return &idx[i-1]
+}
+
+// find ищет версию по названию или ID.
+func (idx versionIndex) find(labelOrID string) *figma.Version {
+	for i := len(idx) - 1; i >= 0; i-- {
+		if idx[i].Label == labelOrID || idx[i].ID == labelOrID {
+			return &idx[i]
+		}
+	}
+	return nil
+}
+
+// filterSinceVersion оставляет треды, созданные после указанной версии.
+// Если в файле такой версии нет, возвращает ошибку: файл попадает
+// в список сбоев, а не в отчёт целиком.
+func filterSinceVersion(threads []figma.Thread, versions versionIndex, labelOrID string) ([]figma.Thread, error) {
+	v := versions.find(labelOrID)
+	if v == nil {
+		return nil, fmt.Errorf("%w: %q", figma.ErrVersionNotFound, labelOrID)
+	}
+
+	var filtered []figma.Thread
+	for _, thread := range threads {
+		if thread.Root.CreatedAt.After(v.CreatedAt) {
+			filtered = append(filtered, thread)
+		}
+	}
+	return filtered, nil
+}
+
+// countSince считает треды, начатые не раньше момента t.
+func countSince(threads []figma.Thread, t time.Time) int {
+	count := 0
+	for _, thread := range threads {
+		if !thread.Root.CreatedAt.Before(t) {
+			count++
+		}
+	}
+	return count
+}
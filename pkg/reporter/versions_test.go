@@ -0,0 +1,65 @@
+package reporter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/figma"
+)
+
+func TestSinceVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		since       string
+		want        string
+		wantFailure bool
+	}{
+		{name: "by label", since: "Review 1", want: "2|3"},
+		{name: "by id", since: "v2", want: "3"},
+		{name: "unknown label", since: "Release 9", wantFailure: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFigma(t)
+			r := f.reporter("comment_number")
+			r.SinceVersion = tt.since
+
+			entries, failures, err := r.gather()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(column(r, entries, "comment_number"), "|"); got != tt.want {
+				t.Errorf("comments = %q, want %q", got, tt.want)
+			}
+			if tt.wantFailure {
+				if len(failures) != 1 || failures[0].ID != "KEY" || !errors.Is(failures[0].Err, figma.ErrVersionNotFound) {
+					t.Fatalf("failures = %v, want version not found for KEY", failures)
+				}
+				if reason := figma.Reason(failures[0].Err); reason != "version not found" {
+					t.Errorf("reason = %q", reason)
+				}
+			} else if len(failures) > 0 {
+				t.Errorf("unexpected failures: %v", failures)
+			}
+		})
+	}
+}
+
+func TestVersionFields(t *testing.T) {
+	f := newFakeFigma(t)
+	r := f.reporter("version_label", "comments_since_version")
+
+	entries, _, err := r.gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Безымянная версия v0 не учитывается
+	if got := strings.Join(column(r, entries, "version_label"), "|"); got != "|Review 1|Review 2" {
+		t.Errorf("version_label = %q", got)
+	}
+	if got := strings.Join(column(r, entries, "comments_since_version"), "|"); got != "|3|2" {
+		t.Errorf("comments_since_version = %q", got)
+	}
+}
@@ -0,0 +1,64 @@
+package reporter
+
+import (
+	"bytes"
+	"log"
+
+	"github.com/tealeg/xlsx"
+)
+
+type xlsxRenderer struct{}
+
+func (xlsxRenderer) Extension() string { return "xlsx" }
+
+func (xlsxRenderer) MIMEType() string {
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+func (xlsxRenderer) Render(rep *Report) ([]byte, error) {
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("Comments")
+	if err != nil {
+		return nil, err
+	}
+
+	headerRow := sheet.AddRow()
+	for _, title := range rep.Header() {
+		cell := headerRow.AddCell()
+		cell.Value = title
+	}
+
+	var images []cellImage
+	for i, e := range rep.entries {
+		row := sheet.AddRow()
+		for col, field := range rep.Fields() {
+			cell := row.AddCell()
+			cell.Value = rep.reporter.getFieldValue(e, field)
+
+			if field.Name == "thumbnail" && e.Thumbnail != "" {
+				img, err := readCellImage(i+1, col, e.Thumbnail)
+				if err != nil {
+					log.Printf("Error embedding thumbnail for node %s: %v", e.NodeID, err)
+					continue
+				}
+				images = append(images, img)
+				sheet.SetColWidth(col, col, float64(thumbnailMaxWidth)/7)
+				if height := float64(img.Height) * 0.75; height > row.Height {
+					row.SetHeight(height)
+				}
+			}
+		}
+	}
+
+	if len(rep.failures) > 0 {
+		if err := writeErrorsSheet(file, rep.failures); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := file.Write(&buf); err != nil {
+		return nil, err
+	}
+	return embedImages(buf.Bytes(), images)
+}
@@ -0,0 +1,173 @@
+package reporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"strings"
+)
+
+// tealeg/xlsx не умеет вставлять изображения, поэтому миниатюры
+// добавляются в готовый файл: рисунок с привязкой к ячейкам листа.
+
+const (
+	emuPerPixel       = 9525
+	thumbnailMaxWidth = 160
+	drawingRelID      = "rIdThumbnails"
+)
+
+type cellImage struct {
+	Row, Col int
+	Data     []byte
+	Width    int
+	Height   int
+	Ext      string
+}
+
+func newCellImage(row, col int, data []byte) (cellImage, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return cellImage{}, err
+	}
+	img := cellImage{Row: row, Col: col, Data: data, Width: cfg.Width, Height: cfg.Height, Ext: format}
+	if img.Ext == "jpeg" {
+		img.Ext = "jpg"
+	}
+	if img.Width > thumbnailMaxWidth {
+		img.Height = img.Height * thumbnailMaxWidth / img.Width
+		img.Width = thumbnailMaxWidth
+	}
+	return img, nil
+}
+
+func readCellImage(row, col int, path string) (cellImage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cellImage{}, err
+	}
+	return newCellImage(row, col, data)
+}
+
+// embedImages добавляет изображения на первый лист книги.
+func embedImages(workbook []byte, images []cellImage) ([]byte, error) {
+	if len(images) == 0 {
+		return workbook, nil
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(workbook), int64(len(workbook)))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		switch f.Name {
+		case "[Content_Types].xml":
+			data = []byte(addImageContentTypes(string(data)))
+		case "xl/worksheets/sheet1.xml":
+			data = []byte(addDrawingRef(string(data)))
+		}
+		if err := writeZipFile(zw, f.Name, data); err != nil {
+			return nil, err
+		}
+	}
+
+	parts := map[string]string{
+		"xl/worksheets/_rels/sheet1.xml.rels": relationships(fmt.Sprintf(
+			`<Relationship Id="%s" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/drawing" Target="../drawings/drawing1.xml"/>`,
+			drawingRelID)),
+		"xl/drawings/drawing1.xml": drawingXML(images),
+	}
+
+	var rels strings.Builder
+	for i, img := range images {
+		fmt.Fprintf(&rels,
+			`<Relationship Id="rIdImage%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="../media/thumbnail%d.%s"/>`,
+			i+1, i+1, img.Ext)
+		if err := writeZipFile(zw, fmt.Sprintf("xl/media/thumbnail%d.%s", i+1, img.Ext), img.Data); err != nil {
+			return nil, err
+		}
+	}
+	parts["xl/drawings/_rels/drawing1.xml.rels"] = relationships(rels.String())
+
+	for name, content := range parts {
+		if err := writeZipFile(zw, name, []byte(content)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func addImageContentTypes(types string) string {
+	extra := `<Default Extension="png" ContentType="image/png"/>` +
+		`<Default Extension="jpg" ContentType="image/jpeg"/>` +
+		`<Override PartName="/xl/drawings/drawing1.xml" ContentType="application/vnd.openxmlformats-officedocument.drawing+xml"/>`
+	return strings.Replace(types, "</Types>", extra+"</Types>", 1)
+}
+
+func addDrawingRef(sheet string) string {
+	const ns = `xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+	if !strings.Contains(sheet, ns) {
+		sheet = strings.Replace(sheet, "<worksheet ", "<worksheet "+ns+" ", 1)
+	}
+	return strings.Replace(sheet, "</worksheet>", fmt.Sprintf(`<drawing r:id="%s"/></worksheet>`, drawingRelID), 1)
+}
+
+func relationships(body string) string {
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		body + `</Relationships>`
+}
+
+func drawingXML(images []cellImage) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	b.WriteString(`<xdr:wsDr xmlns:xdr="http://schemas.openxmlformats.org/drawingml/2006/spreadsheetDrawing" ` +
+		`xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
+	for i, img := range images {
+		fmt.Fprintf(&b, `<xdr:oneCellAnchor>`+
+			`<xdr:from><xdr:col>%d</xdr:col><xdr:colOff>0</xdr:colOff><xdr:row>%d</xdr:row><xdr:rowOff>0</xdr:rowOff></xdr:from>`+
+			`<xdr:ext cx="%d" cy="%d"/>`+
+			`<xdr:pic><xdr:nvPicPr><xdr:cNvPr id="%d" name="Thumbnail %d"/><xdr:cNvPicPr><a:picLocks noChangeAspect="1"/></xdr:cNvPicPr></xdr:nvPicPr>`+
+			`<xdr:blipFill><a:blip r:embed="rIdImage%d"/><a:stretch><a:fillRect/></a:stretch></xdr:blipFill>`+
+			`<xdr:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="%d" cy="%d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></xdr:spPr></xdr:pic>`+
+			`<xdr:clientData/></xdr:oneCellAnchor>`,
+			img.Col, img.Row,
+			img.Width*emuPerPixel, img.Height*emuPerPixel,
+			i+2, i+1,
+			i+1,
+			img.Width*emuPerPixel, img.Height*emuPerPixel)
+	}
+	b.WriteString(`</xdr:wsDr>`)
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	EventPing        = "PING"
+	EventFileComment = "FILE_COMMENT"
+)
+
+type User struct {
+	ID     string `json:"id"`
+	Handle string `json:"handle"`
+}
+
+// Fragment — часть текста комментария: обычный текст или упоминание.
+type Fragment struct {
+	Text    string `json:"text,omitempty"`
+	Mention string `json:"mention,omitempty"`
+}
+
+type Event struct {
+	EventType   string     `json:"event_type"`
+	Passcode    string     `json:"passcode"`
+	Timestamp   time.Time  `json:"timestamp"`
+	WebhookID   string     `json:"webhook_id"`
+	FileKey     string     `json:"file_key"`
+	FileName    string     `json:"file_name"`
+	CommentID   string     `json:"comment_id"`
+	ParentID    string     `json:"parent_id"`
+	Comment     []Fragment `json:"comment"`
+	Mentions    []User     `json:"mentions"`
+	TriggeredBy User       `json:"triggered_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Message собирает текст комментария, подставляя имена упомянутых.
+func (e Event) Message() string {
+	handles := make(map[string]string, len(e.Mentions))
+	for _, m := range e.Mentions {
+		handles[m.ID] = m.Handle
+	}
+
+	var b strings.Builder
+	for _, f := range e.Comment {
+		if f.Mention != "" {
+			handle := handles[f.Mention]
+			if handle == "" {
+				handle = f.Mention
+			}
+			b.WriteString("@" + handle)
+			continue
+		}
+		b.WriteString(f.Text)
+	}
+	return b.String()
+}
+
+// key определяет событие при повторной доставке.
+func (e Event) key() string {
+	return strings.Join([]string{e.WebhookID, e.EventType, e.FileKey, e.CommentID, e.Timestamp.Format(time.RFC3339Nano)}, "|")
+}
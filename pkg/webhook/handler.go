@@ -0,0 +1,132 @@
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/notify"
+)
+
+// Повторные доставки Figma приходят в течение нескольких часов
+const dedupWindow = 24 * time.Hour
+
+// Handler принимает вебхуки Figma, проверяет passcode, отбрасывает
+// повторные доставки и рассылает события FILE_COMMENT уведомителям.
+// Figma получает ответ сразу, уведомления уходят в фоне.
+type Handler struct {
+	Passcode  string
+	Notifiers []notify.Notifier
+	// Если задан, в нём сохраняются пришедшие комментарии и ключи
+	// доставок; без него повторы отсеиваются только до перезапуска
+	Store *Store
+
+	mu      sync.Mutex
+	seen    map[string]time.Time
+	pending sync.WaitGroup
+}
+
+func NewHandler(passcode string, notifiers []notify.Notifier, store *Store) *Handler {
+	return &Handler{
+		Passcode:  passcode,
+		Notifiers: notifiers,
+		Store:     store,
+		seen:      make(map[string]time.Time),
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var event Event
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&event); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(event.Passcode), []byte(h.Passcode)) != 1 {
+		log.Printf("Rejected webhook %s: passcode mismatch", event.WebhookID)
+		http.Error(w, "invalid passcode", http.StatusForbidden)
+		return
+	}
+
+	if h.duplicate(event) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	switch event.EventType {
+	case EventPing:
+		log.Printf("Webhook %s ping received", event.WebhookID)
+	case EventFileComment:
+		if h.Store != nil {
+			if err := h.Store.Apply(event); err != nil {
+				log.Printf("Error saving comment %s: %v", event.CommentID, err)
+			}
+		}
+		h.pending.Add(1)
+		go func() {
+			defer h.pending.Done()
+			h.notify(event)
+		}()
+	default:
+		log.Printf("Ignoring webhook event %s", event.EventType)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Wait дожидается отправки уведомлений по уже принятым событиям.
+func (h *Handler) Wait() {
+	h.pending.Wait()
+}
+
+func (h *Handler) duplicate(event Event) bool {
+	key := event.key()
+	now := time.Now()
+
+	if h.Store != nil {
+		seen, err := h.Store.markDelivered(key, now, dedupWindow)
+		if err == nil {
+			return seen
+		}
+		log.Printf("Error reading webhook state, using in-memory deduplication: %v", err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for k, at := range h.seen {
+		if now.Sub(at) > dedupWindow {
+			delete(h.seen, k)
+		}
+	}
+	if _, ok := h.seen[key]; ok {
+		return true
+	}
+	h.seen[key] = now
+	return false
+}
+
+func (h *Handler) notify(event Event) {
+	n := notify.Notification{
+		Title: fmt.Sprintf("New comment in %s", event.FileName),
+		Text:  fmt.Sprintf("%s: %s", event.TriggeredBy.Handle, event.Message()),
+		Link:  fmt.Sprintf("https://www.figma.com/design/%s#%s", event.FileKey, event.CommentID),
+	}
+	if event.ParentID != "" {
+		n.Title = fmt.Sprintf("New reply in %s", event.FileName)
+	}
+
+	for _, notifier := range h.Notifiers {
+		if err := notifier.Notify(n); err != nil {
+			log.Printf("Error sending notification for comment %s: %v", event.CommentID, err)
+		}
+	}
+}
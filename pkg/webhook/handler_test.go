@@ -0,0 +1,269 @@
+package webhook
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Hikitak/figma-comment-reporter/pkg/notify"
+)
+
+const testPasscode = "test-passcode"
+
+// recorder запоминает уведомления; если задан release, отправка
+// ждёт, пока его не закроют.
+type recorder struct {
+	mu      sync.Mutex
+	sent    []notify.Notification
+	release chan struct{}
+}
+
+func (r *recorder) Notify(n notify.Notification) error {
+	if r.release != nil {
+		<-r.release
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, n)
+	return nil
+}
+
+func (r *recorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.sent)
+}
+
+func payload(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func post(h http.Handler, method string, body []byte) int {
+	req := httptest.NewRequest(method, "/figma/webhook", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestHandler(t *testing.T) {
+	comment := payload(t, "file_comment.json")
+	ping := payload(t, "ping.json")
+	wrongPasscode := bytes.Replace(comment, []byte(testPasscode), []byte("guess"), 1)
+	reply := bytes.Replace(comment, []byte(`"comment_id": "987654",`), []byte(`"comment_id": "987655", "parent_id": "987654",`), 1)
+
+	type request struct {
+		method string
+		body   []byte
+		want   int
+	}
+	tests := []struct {
+		name      string
+		requests  []request
+		wantSent  int
+		wantTitle string
+		// Комментарии в файле состояния
+		wantStored int
+	}{
+		{
+			name:       "file comment",
+			requests:   []request{{"POST", comment, 200}},
+			wantSent:   1,
+			wantTitle:  "New comment in Checkout",
+			wantStored: 1,
+		},
+		{
+			name:       "reply",
+			requests:   []request{{"POST", reply, 200}},
+			wantSent:   1,
+			wantTitle:  "New reply in Checkout",
+			wantStored: 1,
+		},
+		{
+			name:     "wrong passcode",
+			requests: []request{{"POST", wrongPasscode, 403}},
+		},
+		{
+			name:       "duplicate retry",
+			requests:   []request{{"POST", comment, 200}, {"POST", comment, 200}, {"POST", comment, 200}},
+			wantSent:   1,
+			wantTitle:  "New comment in Checkout",
+			wantStored: 1,
+		},
+		{
+			name:     "ping",
+			requests: []request{{"POST", ping, 200}},
+		},
+		{
+			name:     "not a post",
+			requests: []request{{"GET", nil, 405}},
+		},
+		{
+			name:     "invalid payload",
+			requests: []request{{"POST", []byte("{"), 400}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &recorder{}
+			store := NewStore(filepath.Join(t.TempDir(), "state.json"))
+			h := NewHandler(testPasscode, []notify.Notifier{notifier}, store)
+
+			for i, req := range tt.requests {
+				if got := post(h, req.method, req.body); got != req.want {
+					t.Errorf("request %d: status %d, want %d", i, got, req.want)
+				}
+			}
+			h.Wait()
+
+			if got := notifier.count(); got != tt.wantSent {
+				t.Fatalf("sent %d notifications, want %d", got, tt.wantSent)
+			}
+			if tt.wantSent > 0 {
+				n := notifier.sent[0]
+				if n.Title != tt.wantTitle {
+					t.Errorf("title = %q, want %q", n.Title, tt.wantTitle)
+				}
+				if n.Text != "Ivan: Button label is cut off on mobile, @Anna can you check?" {
+					t.Errorf("text = %q", n.Text)
+				}
+				if !strings.HasPrefix(n.Link, "https://www.figma.com/design/abc123#98765") {
+					t.Errorf("link = %q", n.Link)
+				}
+			}
+
+			state, err := store.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(state.Comments["abc123"]); got != tt.wantStored {
+				t.Errorf("stored %d comments, want %d", got, tt.wantStored)
+			}
+		})
+	}
+}
+
+func TestStoreUpdate(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "state", "webhooks.json"))
+	h := NewHandler(testPasscode, nil, store)
+	if got := post(h, "POST", payload(t, "file_comment.json")); got != 200 {
+		t.Fatalf("status %d", got)
+	}
+
+	state, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := state.Comments["abc123"]["987654"]
+	want := CommentState{
+		FileName:  "Checkout",
+		Author:    "Ivan",
+		Message:   "Button label is cut off on mobile, @Anna can you check?",
+		CreatedAt: time.Date(2024, 5, 14, 9, 12, 44, 0, time.UTC),
+	}
+	if got != want {
+		t.Errorf("stored %+v, want %+v", got, want)
+	}
+	if len(state.Deliveries) != 1 {
+		t.Errorf("deliveries = %v", state.Deliveries)
+	}
+}
+
+// Повтор после перезапуска сервера не рассылается ещё раз: ключи
+// доставок хранятся в файле состояния.
+func TestDeduplicationSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	comment := payload(t, "file_comment.json")
+
+	notifier := &recorder{}
+	first := NewHandler(testPasscode, []notify.Notifier{notifier}, NewStore(path))
+	post(first, "POST", comment)
+	first.Wait()
+
+	restarted := NewHandler(testPasscode, []notify.Notifier{notifier}, NewStore(path))
+	if got := post(restarted, "POST", comment); got != 200 {
+		t.Errorf("retry status %d, want 200", got)
+	}
+	restarted.Wait()
+
+	if got := notifier.count(); got != 1 {
+		t.Errorf("sent %d notifications, want 1", got)
+	}
+}
+
+func TestStoreDropsOldDeliveries(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "state.json"))
+	start := time.Now()
+
+	tests := []struct {
+		key      string
+		at       time.Time
+		wantSeen bool
+	}{
+		{"a", start, false},
+		{"a", start.Add(time.Hour), true},
+		{"b", start.Add(time.Hour), false},
+		// Ключ старше окна удалён и принимается заново
+		{"a", start.Add(dedupWindow + 2*time.Hour), false},
+	}
+	for _, tt := range tests {
+		seen, err := store.markDelivered(tt.key, tt.at, dedupWindow)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen != tt.wantSeen {
+			t.Errorf("%s at %v: seen = %v, want %v", tt.key, tt.at.Sub(start), seen, tt.wantSeen)
+		}
+	}
+}
+
+// Figma получает ответ до того, как уведомления отправлены.
+func TestNotifiesAfterResponse(t *testing.T) {
+	notifier := &recorder{release: make(chan struct{})}
+	h := NewHandler(testPasscode, []notify.Notifier{notifier}, nil)
+
+	done := make(chan int)
+	go func() { done <- post(h, "POST", payload(t, "file_comment.json")) }()
+
+	select {
+	case code := <-done:
+		if code != 200 {
+			t.Errorf("status %d, want 200", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler waited for the notifier")
+	}
+
+	close(notifier.release)
+	h.Wait()
+	if got := notifier.count(); got != 1 {
+		t.Errorf("sent %d notifications, want 1", got)
+	}
+}
+
+func TestEventMessage(t *testing.T) {
+	tests := []struct {
+		event Event
+		want  string
+	}{
+		{Event{Comment: []Fragment{{Text: "plain"}}}, "plain"},
+		{Event{Comment: []Fragment{{Text: "hi "}, {Mention: "1"}}, Mentions: []User{{ID: "1", Handle: "Anna"}}}, "hi @Anna"},
+		{Event{Comment: []Fragment{{Mention: "2"}}}, "@2"},
+	}
+	for _, tt := range tests {
+		if got := tt.event.Message(); got != tt.want {
+			t.Errorf("Message() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type CommentState struct {
+	FileName  string    `json:"file_name"`
+	ParentID  string    `json:"parent_id,omitempty"`
+	Author    string    `json:"author"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// State — содержимое файла состояния сервера вебхуков.
+type State struct {
+	// Ключ файла -> ID комментария -> комментарий
+	Comments map[string]map[string]CommentState `json:"comments"`
+	// Ключи обработанных доставок и время их получения, чтобы повторы
+	// отбрасывались и после перезапуска
+	Deliveries map[string]time.Time `json:"deliveries"`
+}
+
+// Store хранит на диске журнал пришедших комментариев и ключи уже
+// обработанных доставок. Отчёт его не читает: комментарии для отчёта
+// всегда берутся из REST API.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+func (s *Store) Load() (*State, error) {
+	state := &State{}
+	data, err := os.ReadFile(s.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, err
+		}
+	}
+	if state.Comments == nil {
+		state.Comments = map[string]map[string]CommentState{}
+	}
+	if state.Deliveries == nil {
+		state.Deliveries = map[string]time.Time{}
+	}
+	return state, nil
+}
+
+// Apply записывает комментарий из события FILE_COMMENT.
+func (s *Store) Apply(e Event) error {
+	return s.update(func(state *State) bool {
+		if state.Comments[e.FileKey] == nil {
+			state.Comments[e.FileKey] = map[string]CommentState{}
+		}
+		state.Comments[e.FileKey][e.CommentID] = CommentState{
+			FileName:  e.FileName,
+			ParentID:  e.ParentID,
+			Author:    e.TriggeredBy.Handle,
+			Message:   e.Message(),
+			CreatedAt: e.CreatedAt,
+		}
+		return true
+	})
+}
+
+// markDelivered запоминает ключ доставки и сообщает, был ли он уже
+// получен в пределах window. Более старые ключи удаляются.
+func (s *Store) markDelivered(key string, now time.Time, window time.Duration) (bool, error) {
+	seen := false
+	err := s.update(func(state *State) bool {
+		for k, at := range state.Deliveries {
+			if now.Sub(at) > window {
+				delete(state.Deliveries, k)
+			}
+		}
+		if _, ok := state.Deliveries[key]; ok {
+			seen = true
+			return false
+		}
+		state.Deliveries[key] = now
+		return true
+	})
+	return seen, err
+}
+
+// update читает состояние, применяет change и, если тот вернул true,
+// атомарно записывает файл.
+func (s *Store) update(change func(*State) bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if !change(state) {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}